@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/christoffer/simple-i18n/internal"
+)
+
+func runExport(args []string) {
+	flags := newFlagSet("export", "", "Exports the translations of all locales as CSV, with a row per key and a column per locale, for\ntranslators to fill in the empty cells.")
+	project := addProjectFlags(flags)
+
+	var output string
+	flags.StringVar(&output, "out", "", "File to write the CSV to (defaults to standard output)")
+
+	_ = flags.Parse(args)
+
+	config := project.config()
+	files, err := internal.FindTranslationFiles(config.Input, config.Exclude)
+	if err != nil {
+		bail("Error reading %s: %v", config.Input, err)
+	}
+	base, err := internal.BaseTranslationFile(files, config.Base)
+	if err != nil {
+		bail("Error finding the base locale: %v", err)
+	}
+
+	var writer io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			bail("Error creating %s: %v", output, err)
+		}
+		defer file.Close()
+		writer = file
+	}
+	if err := internal.ExportCSV(writer, files, base); err != nil {
+		bail("Error exporting translations: %v", err)
+	}
+}
+
+func runImport(args []string) {
+	flags := newFlagSet("import", " <file.csv>", "Imports translations from CSV in the format of export. Non-empty cells replace the translations\nin the TOML files, which are rewritten, so comments in them are lost, except for translations that are\nstill commented out. Files of new locales are created. Nothing is written unless all translations are valid.")
+	project := addProjectFlags(flags)
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	csvFile, err := os.Open(flags.Arg(0))
+	if err != nil {
+		bail("Error opening %s: %v", flags.Arg(0), err)
+	}
+	defer csvFile.Close()
+	imported, err := internal.ImportCSV(csvFile)
+	if err != nil {
+		bail("Error importing %s: %v", flags.Arg(0), err)
+	}
+
+	config := project.config()
+	files, err := internal.FindTranslationFiles(config.Input, config.Exclude)
+	if err != nil {
+		bail("Error reading %s: %v", config.Input, err)
+	}
+
+	locales := make([]string, 0, len(imported))
+	for locale := range imported {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	updates := make([]importUpdate, 0, len(locales))
+	for _, locale := range locales {
+		file, exists, err := internal.FindTranslationFile(files, locale)
+		if err != nil {
+			bail("Invalid locale: %v", err)
+		}
+		translations, untranslated := make(map[string]string), make(map[string]string)
+		if exists {
+			if translations, err = internal.ReadTranslations(file.Path); err != nil {
+				bail("Error reading %s: %v", file.Path, err)
+			}
+			if untranslated, err = internal.ReadUntranslated(file.Path); err != nil {
+				bail("Error reading %s: %v", file.Path, err)
+			}
+		} else if file, err = internal.NewTranslationFile(config.Input, locale); err != nil {
+			bail("Invalid locale: %v", err)
+		}
+
+		changed := 0
+		for key, value := range imported[locale] {
+			if translations[key] != value {
+				translations[key] = value
+				changed++
+			}
+			delete(untranslated, key)
+		}
+		if changed == 0 {
+			continue
+		}
+		encoded, err := internal.EncodeTranslations(translations, untranslated)
+		if err != nil {
+			bail("Error encoding translations: %v", err)
+		}
+		updates = append(updates, importUpdate{file: file, content: encoded, changed: changed})
+	}
+
+	// Invalid translations are better to find out about now than when generating, before they're written
+	if err := validateImport(config, files, updates); err != nil {
+		bail("Imported translations are invalid, nothing was written:\n%s", err)
+	}
+	for _, update := range updates {
+		if err := os.WriteFile(update.file.Path, update.content, 0644); err != nil {
+			bail("Error writing %s: %v", update.file.Path, err)
+		}
+		fmt.Printf("Updated %d translations in %s\n", update.changed, update.file.Path)
+	}
+}
+
+// importUpdate is the new content of a translation file, from an import.
+type importUpdate struct {
+	file    internal.TranslationFile
+	content []byte
+	changed int
+}
+
+// validateImport validates the translations as they would be after the updates, in a copy of the input dir.
+func validateImport(config internal.Config, files []internal.TranslationFile, updates []importUpdate) error {
+	tempDir, err := os.MkdirTemp("", "simple-i18n-import")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, filepath.Base(file.Path)), content, 0644); err != nil {
+			return err
+		}
+	}
+	for _, update := range updates {
+		if err := os.WriteFile(filepath.Join(tempDir, filepath.Base(update.file.Path)), update.content, 0644); err != nil {
+			return err
+		}
+	}
+	_, err = internal.ProcessTomlDir(tempDir, config.ProcessOptions())
+	return err
+}
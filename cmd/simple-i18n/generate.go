@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/christoffer/simple-i18n/internal"
+)
+
+func runGenerate(args []string) {
+	flags := newFlagSet("generate", "", "Generates the Go package with the translations, and removes generated files that are no longer generated.")
+	project := addProjectFlags(flags)
+
+	var outputDir string
+	flags.StringVar(&outputDir, "o", "i18n", "Output directory for generated files")
+
+	var packageName string
+	flags.StringVar(&packageName, "p", "", "Package name for generated files (defaults to output directory name)")
+
+	var check bool
+	flags.BoolVar(&check, "check", false, "Check that the output directory is up to date, printing a diff and failing if not, without writing anything")
+
+	_ = flags.Parse(args)
+
+	config := project.config()
+	if !isSet(flags, "o") && config.Output.Dir != "" {
+		outputDir = config.Output.Dir
+	}
+	if !isSet(flags, "p") {
+		packageName = config.Output.Package
+	}
+	if packageName == "" {
+		packageName = filepath.Base(outputDir)
+	}
+
+	validatePackageName(packageName)
+
+	processResult := process(config, "Generation prevented")
+
+	files, err := internal.GenerateFiles(processResult, packageName, config.Verbose)
+	if err != nil {
+		bail("Error %v", err)
+	}
+
+	allTags := sortedTags(processResult)
+	if check {
+		diff, err := internal.DiffOutputDir(outputDir, files)
+		if err != nil {
+			bail("Error checking output directory: %v", err)
+		}
+		if diff != "" {
+			fmt.Print(diff)
+			bail("Generated files in %s are out of date, run simple-i18n to update them", outputDir)
+		}
+		fmt.Printf("Generated files in %s are up to date for locales: %s\n", outputDir, strings.Join(allTags, ", "))
+		return
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		bail("Error creating output directory: %s", err)
+	}
+	for _, file := range files {
+		writeFile(file.Name, outputDir, file.Content, config.Verbose)
+	}
+	// Files of removed locales would otherwise still be compiled, against an interface they may not implement
+	removed, err := internal.RemoveStaleFiles(outputDir, files)
+	if err != nil {
+		bail("Error removing stale files: %v", err)
+	}
+	for _, path := range removed {
+		fmt.Printf("Removed stale file %s\n", path)
+	}
+
+	fmt.Printf("Generated translation files for locales: %s\n", strings.Join(allTags, ", "))
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/christoffer/simple-i18n/internal"
+)
+
+func runInit(args []string) {
+	flags := newFlagSet("init", "", "Creates "+internal.ConfigFileName+" in the working directory, and the translation file of the base locale.")
+
+	var tomlDir string
+	flags.StringVar(&tomlDir, "i", "translations", "Input dir containing TOML files")
+
+	var outputDir string
+	flags.StringVar(&outputDir, "o", "i18n", "Output directory for generated files")
+
+	var packageName string
+	flags.StringVar(&packageName, "p", "", "Package name for generated files (defaults to output directory name)")
+
+	var baseLocale string
+	flags.StringVar(&baseLocale, "b", "en", "Base locale for translations")
+
+	_ = flags.Parse(args)
+
+	if packageName == "" {
+		packageName = filepath.Base(outputDir)
+	}
+	validatePackageName(packageName)
+
+	if _, err := os.Stat(internal.ConfigFileName); err == nil {
+		bail("%s already exists", internal.ConfigFileName)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		bail("Error reading %s: %v", internal.ConfigFileName, err)
+	}
+
+	baseFile, err := internal.NewTranslationFile(tomlDir, baseLocale)
+	if err != nil {
+		bail("Invalid base locale: %v", err)
+	}
+
+	config := fmt.Sprintf(`# Config for simple-i18n, flags override the options in it
+input = %q
+base = %q
+
+[output]
+dir = %q
+package = %q
+`, filepath.ToSlash(tomlDir), baseFile.Tag, filepath.ToSlash(outputDir), packageName)
+	if err := os.WriteFile(internal.ConfigFileName, []byte(config), 0644); err != nil {
+		bail("Error writing %s: %v", internal.ConfigFileName, err)
+	}
+	fmt.Printf("Created %s\n", internal.ConfigFileName)
+
+	files, err := internal.FindTranslationFiles(tomlDir, nil)
+	if err != nil {
+		bail("Error reading %s: %v", tomlDir, err)
+	}
+	if _, exists, _ := internal.FindTranslationFile(files, baseLocale); exists {
+		return
+	}
+	if err := os.MkdirAll(tomlDir, 0755); err != nil {
+		bail("Error creating input directory: %v", err)
+	}
+	if err := os.WriteFile(baseFile.Path, []byte("greeting = \"Hello {name}\"\n"), 0644); err != nil {
+		bail("Error writing %s: %v", baseFile.Path, err)
+	}
+	fmt.Printf("Created %s, run simple-i18n to generate the %s package\n", baseFile.Path, packageName)
+}
+
+func runAdd(args []string) {
+	flags := newFlagSet("add", " <locale>...", "Adds the translation file of a locale, with the translations of the base locale commented out,\nready to be translated.")
+	project := addProjectFlags(flags)
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	config := project.config()
+	files, err := internal.FindTranslationFiles(config.Input, config.Exclude)
+	if err != nil {
+		bail("Error reading %s: %v", config.Input, err)
+	}
+	base, err := internal.BaseTranslationFile(files, config.Base)
+	if err != nil {
+		bail("Error finding the base locale: %v", err)
+	}
+	baseTranslations, err := internal.ReadTranslations(base.Path)
+	if err != nil {
+		bail("Error reading the base locale: %v", err)
+	}
+
+	for _, name := range flags.Args() {
+		if existing, exists, err := internal.FindTranslationFile(files, name); err != nil {
+			bail("Invalid locale: %v", err)
+		} else if exists {
+			bail("%s already has the translation file %s", name, existing.Path)
+		}
+		file, err := internal.NewTranslationFile(config.Input, name)
+		if err != nil {
+			bail("Invalid locale: %v", err)
+		}
+
+		encoded, err := internal.EncodeTranslations(nil, baseTranslations)
+		if err != nil {
+			bail("Error encoding translations: %v", err)
+		}
+		header := fmt.Sprintf("# Translations for %s, from %s. Translate the commented translations, and uncomment them.\n\n", file.Tag, base.Tag)
+		if err := os.WriteFile(file.Path, append([]byte(header), encoded...), 0644); err != nil {
+			bail("Error writing %s: %v", file.Path, err)
+		}
+
+		fmt.Printf("Created %s with %d translations to translate\n", file.Path, len(baseTranslations))
+		if !strings.Contains(file.Locale, "_") {
+			fmt.Printf("Until they're translated, allow them to be missing with allow_missing in %s, or -allow-missing %s\n", internal.ConfigFileName, file.Tag)
+		}
+	}
+}
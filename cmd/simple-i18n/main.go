@@ -6,79 +6,185 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/christoffer/simple-i18n/internal"
 )
 
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+var commands = []command{
+	{"generate", "Generate the Go package from the translations (the default)", runGenerate},
+	{"validate", "Validate the translations without generating anything", runValidate},
+	{"stats", "Show how much of each locale is translated", runStats},
+	{"init", "Create a config and a first translation file", runInit},
+	{"add", "Add a translation file for a new locale", runAdd},
+	{"export", "Export all translations as CSV, for translators", runExport},
+	{"import", "Import translations from CSV", runImport},
+}
+
 func main() {
-	var tomlDir string
-	flag.StringVar(&tomlDir, "i", "translations", "Input dir containing TOML files")
+	args := os.Args[1:]
+	switch {
+	case len(args) == 0:
+		if config := loadConfig(""); config == nil {
+			usage()
+			os.Exit(1)
+		}
+		runGenerate(args)
+	case args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help":
+		usage()
+	case strings.HasPrefix(args[0], "-"):
+		// Flags without a command, like before there were commands
+		runGenerate(args)
+	case args[0] == "check":
+		// `simple-i18n check ...` is the same as `simple-i18n generate -check ...`
+		runGenerate(append([]string{"-check"}, args[1:]...))
+	default:
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				cmd.run(args[1:])
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Unknown command %s\n\n", args[0])
+		usage()
+		os.Exit(1)
+	}
+}
 
-	var outputDir string
-	flag.StringVar(&outputDir, "o", "i18n", "Output directory for generated files")
+func usage() {
+	fmt.Printf("Usage: simple-i18n <command> [options]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Printf("  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Printf("\nRun simple-i18n <command> -h for the options of a command.\n")
+}
 
-	var verbose bool
-	flag.BoolVar(&verbose, "v", false, "Enable verbose output")
+// newFlagSet returns the flags of a command, which prints its usage for -h.
+func newFlagSet(name string, arguments string, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: simple-i18n %s [options]%s\n\n%s\n\nOptions:\n", name, arguments, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
-	var packageName string
-	flag.StringVar(&packageName, "p", "", "Package name for generated files (defaults to output directory name)")
+// projectFlags are the flags of the commands that read the translations. They override the config.
+type projectFlags struct {
+	flags        *flag.FlagSet
+	configPath   string
+	tomlDir      string
+	baseLocale   string
+	allowMissing string
+	verbose      bool
+}
 
-	var baseLocale string
-	flag.StringVar(&baseLocale, "b", "", "Base locale for translations (defaults to the first locale found in input dir)")
+func addProjectFlags(flags *flag.FlagSet) *projectFlags {
+	p := &projectFlags{flags: flags}
+	flags.StringVar(&p.configPath, "config", "", "Config file (defaults to "+internal.ConfigFileName+" in the working directory or the closest of its parents)")
+	flags.StringVar(&p.tomlDir, "i", "translations", "Input dir containing TOML files")
+	flags.StringVar(&p.baseLocale, "b", "", "Base locale for translations (defaults to the first locale found in input dir)")
+	flags.StringVar(&p.allowMissing, "allow-missing", "", "Comma-separated locales that may be missing translations, which then fall back to the base locale (or 'all')")
+	flags.BoolVar(&p.verbose, "v", false, "Enable verbose output")
+	return p
+}
 
-	flag.Parse()
+// isSet returns whether a flag was passed, rather than having its default value.
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
 
-	if len(os.Args) < 2 {
-		fmt.Printf("Usage: simple-i18n\n")
-		flag.PrintDefaults()
-		os.Exit(1)
+// config returns the config, with the flags that were passed applied to it.
+func (p *projectFlags) config() internal.Config {
+	config := internal.Config{Input: p.tomlDir}
+	if loaded := loadConfig(p.configPath); loaded != nil {
+		config = *loaded
+		if isSet(p.flags, "i") || config.Input == "" {
+			config.Input = p.tomlDir
+		}
 	}
-
-	if packageName == "" {
-		packageName = filepath.Base(outputDir)
+	if isSet(p.flags, "b") {
+		config.Base = p.baseLocale
 	}
-
-	validatePackageName(packageName)
-
-	err := os.MkdirAll(outputDir, 0755)
-	if err != nil {
-		bail("Error creating output directory: %s", err)
+	if isSet(p.flags, "v") {
+		config.Verbose = p.verbose
 	}
+	if p.allowMissing != "" {
+		config.AllowMissing = nil
+		for _, locale := range strings.Split(p.allowMissing, ",") {
+			config.AllowMissing = append(config.AllowMissing, strings.TrimSpace(locale))
+		}
+	}
+	return config
+}
 
-	processResult, err := internal.ProcessTomlDir(tomlDir, baseLocale)
+// process parses and validates the translations, which all commands that need valid translations share.
+func process(config internal.Config, failure string) internal.ProcessedLocale {
+	processResult, err := internal.ProcessTomlDir(config.Input, config.ProcessOptions())
 	if err != nil {
-		bail("Generation prevented:\n%s", err)
+		bail("%s:\n%s", failure, err)
 	}
 
 	if len(processResult.ParsedFuncsByLocale) == 0 {
-		bail("No TOML files found in %s", tomlDir)
+		bail("No TOML files found in %s", config.Input)
 	}
 
-	allLocales := make([]string, 0)
-	for locale, tomlData := range processResult.ParsedFuncsByLocale {
-		content, err := internal.GetTranslationImpl(tomlData, packageName, verbose)
-		if err != nil {
-			bail("Error generating translation implementation for %s: %v", locale, err)
+	if config.Verbose {
+		for _, locale := range sortedLocales(processResult) {
+			tomlData := processResult.ParsedFuncsByLocale[locale]
+			if inherited := tomlData.InheritedKeys(); len(inherited) > 0 {
+				fmt.Printf("%s inherits %d translations from %s: %s\n", locale, len(inherited), tomlData.Fallback, strings.Join(inherited, ", "))
+			}
 		}
-		writeFile(tomlData.Locale+".go", outputDir, content, verbose)
-		allLocales = append(allLocales, locale)
 	}
+	return processResult
+}
 
-	baseLocaleData := processResult.ParsedFuncsByLocale[processResult.BaseLocale]
-	if content, err := internal.GetBaseTranslation(baseLocaleData, packageName, verbose); err != nil {
-		bail("Error generating base translation interface: %v", err)
-	} else {
-		writeFile("base.go", outputDir, content, verbose)
+// sortedLocales returns the locales, sorted to give the same output every time.
+func sortedLocales(processResult internal.ProcessedLocale) []string {
+	locales := make([]string, 0, len(processResult.ParsedFuncsByLocale))
+	for locale := range processResult.ParsedFuncsByLocale {
+		locales = append(locales, locale)
 	}
+	sort.Strings(locales)
+	return locales
+}
 
-	if content, err := internal.GetTranslator(allLocales, baseLocaleData, packageName, verbose); err != nil {
-		bail("Error generating translator: %v", err)
-	} else {
-		writeFile("translator.go", outputDir, content, verbose)
+func sortedTags(processResult internal.ProcessedLocale) []string {
+	tags := make([]string, 0, len(processResult.ParsedFuncsByLocale))
+	for _, locale := range sortedLocales(processResult) {
+		tags = append(tags, processResult.ParsedFuncsByLocale[locale].Tag)
 	}
+	return tags
+}
 
-	fmt.Printf("Generated translation files for locales: %s\n", strings.Join(allLocales, ", "))
+// loadConfig loads the config file at path, or the one found from the working directory if path is empty.
+// It returns nil if there is none.
+func loadConfig(path string) *internal.Config {
+	if path == "" {
+		found, err := internal.FindConfig(".")
+		if err != nil {
+			bail("Error finding config: %v", err)
+		}
+		if found == "" {
+			return nil
+		}
+		path = found
+	}
+	config, err := internal.LoadConfig(path)
+	if err != nil {
+		bail("Error loading config: %v", err)
+	}
+	return &config
 }
 
 func writeFile(filename string, outputDir string, content []byte, verbose bool) {
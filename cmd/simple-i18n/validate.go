@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+func runValidate(args []string) {
+	flags := newFlagSet("validate", "", "Validates the translations, without generating anything. Fails if any translation has errors, or\nis missing from a locale that isn't allowed to miss translations, e.g. in a pre-commit hook.")
+	project := addProjectFlags(flags)
+	_ = flags.Parse(args)
+
+	processResult := process(project.config(), "Validation failed")
+	fmt.Printf("Translations are valid for locales: %s\n", strings.Join(sortedTags(processResult), ", "))
+}
+
+func runStats(args []string) {
+	flags := newFlagSet("stats", "", "Shows how many translations each locale has of its own, inherits from its fallback, and is missing.\nLocales that aren't allowed to miss translations are counted too, rather than failing.")
+	project := addProjectFlags(flags)
+	_ = flags.Parse(args)
+
+	// Missing translations are what stats are for, e.g. right after add, so they're counted rather than failing
+	config := project.config()
+	config.AllowMissing = []string{"all"}
+	processResult := process(config, "Validation failed")
+	total := len(processResult.ParsedFuncsByLocale[processResult.BaseLocale].Keys())
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "LOCALE\tTRANSLATED\t\tINHERITED\tMISSING\n")
+	for _, locale := range sortedLocales(processResult) {
+		tomlData := processResult.ParsedFuncsByLocale[locale]
+		inherited, missing := len(tomlData.InheritedKeys()), len(tomlData.MissingKeys())
+		translated := total - inherited - missing
+		tag := tomlData.Tag
+		if locale == processResult.BaseLocale {
+			tag += " (base)"
+		}
+		percent := 100
+		if total > 0 {
+			percent = translated * 100 / total
+		}
+		fmt.Fprintf(writer, "%s\t%d/%d\t%d%%\t%d\t%d\n", tag, translated, total, percent, inherited, missing)
+	}
+	_ = writer.Flush()
+}
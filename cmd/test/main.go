@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	"github.com/christoffer/simple-i18n/cmd/test/generated"
 )
 
+type user string
+
+func (u user) String() string { return "@" + string(u) }
+
 func printAllTranslations(t *i18n.T, language string) {
 	fmt.Printf("=== %s ===\n", language)
 	fmt.Printf("Root message: %s\n", t.RootMessage())
@@ -21,17 +28,37 @@ func printAllTranslations(t *i18n.T, language string) {
 	specials := t.Specials()
 
 	fmt.Printf("Count (42): %s\n", specials.Count(42))
+	fmt.Printf("Count (1000000): %s\n", specials.Count(1000000))
 	fmt.Printf("Criteria (1): %s\n", specials.Criteria(1))
 	fmt.Printf("Criteria (0): %s\n", specials.Criteria(0))
 
 	fmt.Printf("Point (1): %s\n", specials.Point(1))
 	fmt.Printf("Point (5): %s\n", specials.Point(5))
 
+	fmt.Printf("Price (apple, 1.5, alice): %s\n", specials.Price("apple", 1.5, user("alice")))
+	fmt.Printf("Price (car, 12345.75, bob): %s\n", specials.Price("car", 12345.75, user("bob")))
+
+	fmt.Printf("Due (2024-03-05 14:30): %s\n", specials.Due(time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)))
+
+	fmt.Printf("Arrived (female, Paris): %s\n", specials.Arrived("female", "Paris"))
+	fmt.Printf("Arrived (unknown, Oslo): %s\n", specials.Arrived("unknown", "Oslo"))
+
+	fmt.Printf("Files (1, 1): %s\n", specials.Files(1, 1))
+	fmt.Printf("Files (3, 2): %s\n", specials.Files(3, 2))
+
 	fmt.Printf("Escaped: %s\n", specials.Ecaped())
+	fmt.Printf("Braces (Alice): %s\n", specials.Braces("Alice"))
 
 	fmt.Printf("Multiline notification (1, Alice): %s\n", specials.MultilineNotification(1, "Alice"))
 	fmt.Printf("Multiline notification (3, Bob): %s\n", specials.MultilineNotification(3, "Bob"))
 
+	settings := t.Settings()
+
+	fmt.Printf("Settings: %s\n", settings.Title())
+	fmt.Printf("Settings > Account: %s\n", settings.Account().Title())
+	fmt.Printf("Settings > Account > Security: %s\n", settings.Account().Security().Title())
+	fmt.Printf("Sessions (2): %s\n", settings.Account().Security().Sessions(2))
+
 	fmt.Println()
 }
 
@@ -46,4 +73,25 @@ func main() {
 		}
 		printAllTranslations(t, lang)
 	}
+
+	for _, header := range []string{"sv-SE,sv;q=0.9,en;q=0.8", "en-AU", "fr-FR,de;q=0.5", "ja"} {
+		locale, confidence := i18n.Match(header)
+		fmt.Printf("Match (%s): %s (%s)\n", header, locale, confidence)
+	}
+	fmt.Printf("SetLanguageFromHeader (en-GB,en;q=0.9): %s, %s\n", t.SetLanguageFromHeader("en-GB,en;q=0.9"), t.RootMessage())
+
+	ctx := i18n.WithLocale(context.Background(), "en-GB")
+	fmt.Printf("FromContext (en-GB): %s (%s)\n", i18n.FromContext(ctx).RootMessage(), i18n.LocaleFromContext(ctx))
+	fmt.Printf("FromContext (none): %s\n", i18n.FromContext(context.Background()).RootMessage())
+
+	// German is allowed to be missing translations, which fall back to the base locale
+	if err := t.SetLanguage("de"); err != nil {
+		fmt.Printf("Error setting language de: %v\n", err)
+		return
+	}
+	fmt.Printf("=== de ===\n")
+	fmt.Printf("Root message: %s\n", t.RootMessage())
+	fmt.Printf("Root message with params (missing): %s\n", t.RootWithParams("Christoffer"))
+	fmt.Printf("Message (3, Mary): %s\n", t.Menu().Message(3, "Mary"))
+	fmt.Printf("Missing keys: %d (e.g. %s)\n", len(i18n.MissingKeys("de")), i18n.MissingKeys("de")[0])
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christoffer/simple-i18n/cmd/test/generated"
+)
+
+// Run with -race. Shares one translator between goroutines that change its language while translating.
+func TestTranslatorConcurrentUse(t *testing.T) {
+	translator := i18n.NewTranslator()
+	languages := []string{"en", "en_uk", "sv", "de"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := translator.SetLanguage(languages[(i+j)%len(languages)]); err != nil {
+					t.Error(err)
+					return
+				}
+				if translator.RootMessage() == "" || translator.Menu().Message(j, "Alice") == "" {
+					t.Error("Expected a translation")
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestForConcurrentUse(t *testing.T) {
+	expected := map[string]string{
+		"en":    "Welcome",
+		"en-GB": "Welcome, mate",
+		"sv-SE": "Välkommen",
+		"ja":    "Välkommen", // Base locale
+	}
+
+	var wg sync.WaitGroup
+	for locale, message := range expected {
+		wg.Add(1)
+		go func(locale string, message string) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if actual := i18n.For(locale).RootMessage(); actual != message {
+					t.Errorf("Expected %q for %s, but got %q", message, locale, actual)
+					return
+				}
+			}
+		}(locale, message)
+	}
+	wg.Wait()
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  i18n.MiddlewareOptions
+		url      string
+		cookie   string
+		header   string
+		expected string
+	}{
+		{name: "header", url: "/", header: "en-GB,en;q=0.9", expected: "en-GB"},
+		{name: "query before header", url: "/?lang=de", header: "en", expected: "de"},
+		{name: "cookie before header", url: "/", cookie: "en", header: "de", expected: "en"},
+		{name: "unsupported query", url: "/?lang=ja", header: "de", expected: "de"},
+		{name: "nothing", url: "/", expected: "sv"},
+		{
+			name:     "path first",
+			options:  i18n.MiddlewareOptions{Sources: []i18n.LocaleSource{i18n.SourcePath, i18n.SourceHeader}},
+			url:      "/en/about",
+			header:   "de",
+			expected: "en",
+		},
+		{
+			name:     "custom query param",
+			options:  i18n.MiddlewareOptions{Sources: []i18n.LocaleSource{i18n.SourceQuery}, QueryParam: "locale"},
+			url:      "/?lang=de&locale=en_uk",
+			expected: "en-GB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.SetContentLanguage = true
+			var locale, message string
+			handler := i18n.Middleware(tt.options)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				locale = i18n.LocaleFromContext(r.Context())
+				message = i18n.FromContext(r.Context()).RootMessage()
+			}))
+
+			request := httptest.NewRequest("GET", tt.url, nil)
+			if tt.cookie != "" {
+				request.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+			}
+			if tt.header != "" {
+				request.Header.Set("Accept-Language", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if locale != tt.expected || message != i18n.For(tt.expected).RootMessage() {
+				t.Errorf("Expected %s, but got %s (%s)", tt.expected, locale, message)
+			}
+			if contentLanguage := recorder.Header().Get("Content-Language"); contentLanguage != tt.expected {
+				t.Errorf("Expected Content-Language %s, but got %s", tt.expected, contentLanguage)
+			}
+		})
+	}
+}
+
+type name string
+
+func (n name) String() string { return string(n) }
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		key      string
+		args     map[string]any
+		expected string
+		err      string
+	}{
+		{key: "root_message", expected: "Welcome"},
+		{key: "menu.message", args: map[string]any{"count": 3, "name": "Mary"}, expected: "The Mary has 3 notifications"},
+		{key: "menu.message", args: map[string]any{"count": 1.0, "name": "John"}, expected: "The John has 1 notification"},
+		{key: "settings.account.security.sessions", args: map[string]any{"count": int64(2)}, expected: "2 active sessions"},
+		{key: "specials.price", args: map[string]any{"item": "car", "price": 12345, "user": name("bob")}, expected: "car costs 12,345 (paid by bob)"},
+		{key: "specials.price", args: map[string]any{"item": "car", "price": math.Inf(1), "user": name("bob")}, expected: "car costs ∞ (paid by bob)"},
+		{key: "specials.price", args: map[string]any{"item": "car", "price": math.NaN(), "user": name("bob")}, expected: "car costs NaN (paid by bob)"},
+		{key: "specials.due", args: map[string]any{"when": time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)}, expected: "Due Tuesday, March 5, 2024, at the latest 2:30 PM"},
+		{key: "menu.missing", err: "unknown translation 'menu.missing'"},
+		{key: "menu.message", args: map[string]any{"count": 3}, err: "missing argument 'name' for 'menu.message'"},
+		{key: "menu.message", args: map[string]any{"count": 1.5, "name": "John"}, err: "argument 'count' for 'menu.message' must be int, but was float64"},
+		{key: "menu.message", args: map[string]any{"count": math.NaN(), "name": "John"}, err: "argument 'count' for 'menu.message' must be int, but was float64"},
+		{key: "menu.message", args: map[string]any{"count": uint64(math.MaxUint64), "name": "John"}, err: "argument 'count' for 'menu.message' must fit in an int, but was 18446744073709551615"},
+		{key: "menu.message", args: map[string]any{"count": 1e30, "name": "John"}, err: "must fit in an int"},
+		{key: "menu.message", args: map[string]any{"count": math.Inf(-1), "name": "John"}, err: "must fit in an int"},
+		{key: "root_message", args: map[string]any{"name": "John"}, err: "unexpected argument 'name' for 'root_message'"},
+	}
+
+	translator := i18n.NewTranslator()
+	if err := translator.SetLanguage("en"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.key, tt.args), func(t *testing.T) {
+			actual, err := translator.Lookup(tt.key, tt.args)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing '%s', but got: %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("Expected %q, but got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	message := i18n.Msg.Settings.Account.Security.Sessions(2)
+	if message.Key() != "settings.account.security.sessions" || message.Args()["count"] != 2 {
+		t.Errorf("Unexpected key or args: %s %v", message.Key(), message.Args())
+	}
+	if actual := message.In(i18n.For("en")); actual != "2 active sessions" {
+		t.Errorf("Unexpected message in en: %q", actual)
+	}
+	if actual := message.String(); actual != "2 aktiva sessioner" {
+		t.Errorf("Unexpected message in the base locale: %q", actual)
+	}
+	if actual := fmt.Sprintf("%s", i18n.Msg.RootWithParams("Alice")); actual != "Välkommen Alice" {
+		t.Errorf("Unexpected formatted message: %q", actual)
+	}
+
+	// A nil fmt.Stringer is translated like by the typed method, rather than falling back to the key
+	expected := i18n.For("en").Specials().Price("car", 12345, nil)
+	if actual := i18n.Msg.Specials.Price("car", 12345, nil).In(i18n.For("en")); actual != expected {
+		t.Errorf("Expected %q for a nil fmt.Stringer, but got %q", expected, actual)
+	}
+	if actual := (i18n.Message{}).In(i18n.For("en")); actual != "" {
+		t.Errorf("Expected the zero message to be empty, but got %q", actual)
+	}
+	if actual := message.In(nil); actual != "2 aktiva sessioner" {
+		t.Errorf("Expected a nil translation to be the base locale, but got %q", actual)
+	}
+}
+
+func TestError(t *testing.T) {
+	var err error = fmt.Errorf("loading sessions: %w", i18n.NewError(i18n.Msg.Settings.Account.Security.Sessions(2), io.EOF))
+
+	var translatable *i18n.Error
+	if !errors.As(err, &translatable) {
+		t.Fatalf("Expected an *i18n.Error in %v", err)
+	}
+	if actual := translatable.Localize(i18n.For("en")); actual != "2 active sessions" {
+		t.Errorf("Unexpected localized error: %q", actual)
+	}
+	if actual := err.Error(); actual != "loading sessions: 2 aktiva sessioner: EOF" {
+		t.Errorf("Unexpected error: %q", actual)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("Expected the error to wrap io.EOF")
+	}
+}
+
+func TestAliases(t *testing.T) {
+	if locale, _ := i18n.Match("nb-NO,nb;q=0.9"); locale != "nb" {
+		t.Errorf("Expected nb, but got %s", locale)
+	}
+	if actual := i18n.For("nb").RootMessage(); actual != "Välkommen" {
+		t.Errorf("Expected the translation of sv, but got %q", actual)
+	}
+	if err := i18n.NewTranslator().SetLanguage("nb"); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/christoffer/simple-i18n
 
 go 1.18
 
-require github.com/BurntSushi/toml v1.5.0
+require (
+	github.com/BurntSushi/toml v1.5.0
+	golang.org/x/text v0.14.0
+)
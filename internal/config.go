@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFileName is the name of the project config file, which is found in the working directory or the
+// closest of its parents.
+const ConfigFileName = "simple-i18n.toml"
+
+// Config is the project config file. Flags override the options in it.
+type Config struct {
+	Input        string   `toml:"input"`
+	Base         string   `toml:"base"`
+	Verbose      bool     `toml:"verbose"`
+	AllowMissing []string `toml:"allow_missing"`
+	// Patterns of file names in the input dir to ignore, see filepath.Match
+	Exclude []string `toml:"exclude"`
+	// Other locales to use the translations of a locale for, e.g. no = "nb"
+	Aliases map[string]string `toml:"aliases"`
+	// The locale that a locale falls back to for missing translations, e.g. de_ch = "de_de"
+	Fallbacks map[string]string `toml:"fallbacks"`
+	Output    OutputConfig      `toml:"output"`
+}
+
+type OutputConfig struct {
+	Dir     string `toml:"dir"`
+	Package string `toml:"package"`
+}
+
+// ProcessOptions returns the options for ProcessTomlDir() in the config.
+func (c Config) ProcessOptions() ProcessOptions {
+	return ProcessOptions{
+		BaseLocale:   c.Base,
+		AllowMissing: c.AllowMissing,
+		Exclude:      c.Exclude,
+		Fallbacks:    c.Fallbacks,
+		Aliases:      c.Aliases,
+	}
+}
+
+// FindConfig returns the path of the config file in dir or the closest of its parents, or "" if there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig reads a config file. The input and output dirs in it are relative to the dir of the file.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	meta, err := toml.DecodeFile(path, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return Config{}, fmt.Errorf("unknown options in config %s: %s", path, strings.Join(keys, ", "))
+	}
+
+	configDir := filepath.Dir(path)
+	if config.Input != "" && !filepath.IsAbs(config.Input) {
+		config.Input = filepath.Join(configDir, config.Input)
+	}
+	if config.Output.Dir != "" && !filepath.IsAbs(config.Output.Dir) {
+		config.Output.Dir = filepath.Join(configDir, config.Output.Dir)
+	}
+	return config, nil
+}
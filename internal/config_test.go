@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+input = "translations"
+base = "sv"
+allow_missing = ["de"]
+exclude = ["*_draft.toml"]
+
+[output]
+dir = "/tmp/i18n"
+package = "texts"
+
+[aliases]
+nb = "sv"
+
+[fallbacks]
+de_ch = "de_de"
+`
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(dir, "cmd", "app")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := FindConfig(nested)
+	if err != nil || path != filepath.Join(dir, ConfigFileName) {
+		t.Fatalf("Expected the config in %s, but got '%s', %v", dir, path, err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Input != filepath.Join(dir, "translations") {
+		t.Errorf("Expected the input dir to be relative to the config, but got %s", config.Input)
+	}
+	if config.Output.Dir != "/tmp/i18n" || config.Output.Package != "texts" {
+		t.Errorf("Unexpected output: %+v", config.Output)
+	}
+	options := config.ProcessOptions()
+	if options.BaseLocale != "sv" || options.AllowMissing[0] != "de" || options.Exclude[0] != "*_draft.toml" ||
+		options.Aliases["nb"] != "sv" || options.Fallbacks["de_ch"] != "de_de" {
+		t.Errorf("Unexpected options: %+v", options)
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		toml          string
+		errorContains string
+	}{
+		{name: "unknown option", toml: "input = \"toml\"\nouput = \"i18n\"", errorContains: "unknown options in config"},
+		{name: "unknown nested option", toml: "[output]\npkg = \"i18n\"", errorContains: "output.pkg"},
+		{name: "wrong type", toml: "allow_missing = \"de\"", errorContains: "failed to read config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			if err := os.WriteFile(path, []byte(tt.toml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing '%s', but got: %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package internal
+
+import "strings"
+
+// Widths of date and time formats, as defined by CLDR.
+const (
+	WidthShort  = "short"
+	WidthMedium = "medium"
+	WidthLong   = "long"
+	WidthFull   = "full"
+)
+
+// dateConventions are the CLDR patterns and names used to format dates and times in a locale.
+// Patterns use the CLDR syntax, e.g. "d MMMM y" or "h:mm a".
+type dateConventions struct {
+	Date map[string]string // Date patterns by width
+	Time map[string]string // Time patterns by width
+	// How a date ({1}) and a time ({0}) are combined, by width
+	DateTime   map[string]string
+	Months     []string
+	MonthsAbbr []string
+	Days       []string // Starting with Sunday
+	DaysAbbr   []string
+	DayPeriods []string // AM and PM
+}
+
+// datePattern returns the CLDR pattern for a date style ("date", "time" or "datetime") and width.
+func (d dateConventions) datePattern(style string, width string) string {
+	switch style {
+	case "date":
+		return d.Date[width]
+	case "time":
+		return d.Time[width]
+	}
+	combined := d.DateTime[width]
+	combined = strings.Replace(combined, "{1}", d.Date[width], 1)
+	return strings.Replace(combined, "{0}", d.Time[width], 1)
+}
+
+var time24 = map[string]string{
+	WidthShort:  "HH:mm",
+	WidthMedium: "HH:mm:ss",
+	WidthLong:   "HH:mm:ss z",
+	WidthFull:   "HH:mm:ss zzzz",
+}
+
+// Date conventions keyed by locale or language. Locales without conventions use ISO 8601 like
+// patterns, since those don't need any names.
+var dateConventionsByLocale = map[string]dateConventions{
+	"en": {
+		Date: map[string]string{
+			WidthShort:  "M/d/yy",
+			WidthMedium: "MMM d, y",
+			WidthLong:   "MMMM d, y",
+			WidthFull:   "EEEE, MMMM d, y",
+		},
+		Time: map[string]string{
+			WidthShort:  "h:mm a",
+			WidthMedium: "h:mm:ss a",
+			WidthLong:   "h:mm:ss a z",
+			WidthFull:   "h:mm:ss a zzzz",
+		},
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1} 'at' {0}",
+			WidthFull:   "{1} 'at' {0}",
+		},
+		Months:     []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		MonthsAbbr: []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Days:       []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		DaysAbbr:   []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"en_gb": {
+		Date: map[string]string{
+			WidthShort:  "dd/MM/y",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d MMMM y",
+			WidthFull:   "EEEE d MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1} 'at' {0}",
+			WidthFull:   "{1} 'at' {0}",
+		},
+		Months:     []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		MonthsAbbr: []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sept", "Oct", "Nov", "Dec"},
+		Days:       []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		DaysAbbr:   []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		DayPeriods: []string{"am", "pm"},
+	},
+	"sv": {
+		Date: map[string]string{
+			WidthShort:  "y-MM-dd",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d MMMM y",
+			WidthFull:   "EEEE d MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} 'kl'. {0}",
+			WidthFull:   "{1} 'kl'. {0}",
+		},
+		Months:     []string{"januari", "februari", "mars", "april", "maj", "juni", "juli", "augusti", "september", "oktober", "november", "december"},
+		MonthsAbbr: []string{"jan.", "feb.", "mars", "apr.", "maj", "juni", "juli", "aug.", "sep.", "okt.", "nov.", "dec."},
+		Days:       []string{"söndag", "måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag"},
+		DaysAbbr:   []string{"sön", "mån", "tis", "ons", "tors", "fre", "lör"},
+		DayPeriods: []string{"fm", "em"},
+	},
+	"de": {
+		Date: map[string]string{
+			WidthShort:  "dd.MM.yy",
+			WidthMedium: "dd.MM.y",
+			WidthLong:   "d. MMMM y",
+			WidthFull:   "EEEE, d. MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1} 'um' {0}",
+			WidthFull:   "{1} 'um' {0}",
+		},
+		Months:     []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		MonthsAbbr: []string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		Days:       []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		DaysAbbr:   []string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"fr": {
+		Date: map[string]string{
+			WidthShort:  "dd/MM/y",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d MMMM y",
+			WidthFull:   "EEEE d MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} 'à' {0}",
+			WidthFull:   "{1} 'à' {0}",
+		},
+		Months:     []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		MonthsAbbr: []string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Days:       []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		DaysAbbr:   []string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"es": {
+		Date: map[string]string{
+			WidthShort:  "d/M/yy",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d 'de' MMMM 'de' y",
+			WidthFull:   "EEEE, d 'de' MMMM 'de' y",
+		},
+		Time: map[string]string{
+			WidthShort:  "H:mm",
+			WidthMedium: "H:mm:ss",
+			WidthLong:   "H:mm:ss z",
+			WidthFull:   "H:mm:ss (zzzz)",
+		},
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1}, {0}",
+			WidthFull:   "{1}, {0}",
+		},
+		Months:     []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		MonthsAbbr: []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		Days:       []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		DaysAbbr:   []string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		DayPeriods: []string{"a. m.", "p. m."},
+	},
+	"pl": {
+		Date: map[string]string{
+			WidthShort:  "d.MM.y",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d MMMM y",
+			WidthFull:   "EEEE, d MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1} 'o' {0}",
+			WidthFull:   "{1} 'o' {0}",
+		},
+		// Genitive, as used in dates
+		Months:     []string{"stycznia", "lutego", "marca", "kwietnia", "maja", "czerwca", "lipca", "sierpnia", "września", "października", "listopada", "grudnia"},
+		MonthsAbbr: []string{"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru"},
+		Days:       []string{"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota"},
+		DaysAbbr:   []string{"niedz.", "pon.", "wt.", "śr.", "czw.", "pt.", "sob."},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"ja": {
+		Date: map[string]string{
+			WidthShort:  "y/MM/dd",
+			WidthMedium: "y/MM/dd",
+			WidthLong:   "y年M月d日",
+			WidthFull:   "y年M月d日EEEE",
+		},
+		Time: map[string]string{
+			WidthShort:  "H:mm",
+			WidthMedium: "H:mm:ss",
+			WidthLong:   "H:mm:ss z",
+			WidthFull:   "H時mm分ss秒 zzzz",
+		},
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} {0}",
+			WidthFull:   "{1} {0}",
+		},
+		Months:     []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		MonthsAbbr: []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Days:       []string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		DaysAbbr:   []string{"日", "月", "火", "水", "木", "金", "土"},
+		DayPeriods: []string{"午前", "午後"},
+	},
+	"it": {
+		Date: map[string]string{
+			WidthShort:  "dd/MM/yy",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d MMMM y",
+			WidthFull:   "EEEE d MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1} {0}",
+			WidthFull:   "{1} {0}",
+		},
+		Months:     []string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		MonthsAbbr: []string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		Days:       []string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		DaysAbbr:   []string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"pt": {
+		Date: map[string]string{
+			WidthShort:  "dd/MM/y",
+			WidthMedium: "d 'de' MMM 'de' y",
+			WidthLong:   "d 'de' MMMM 'de' y",
+			WidthFull:   "EEEE, d 'de' MMMM 'de' y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} {0}",
+			WidthFull:   "{1} {0}",
+		},
+		Months:     []string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		MonthsAbbr: []string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+		Days:       []string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		DaysAbbr:   []string{"dom.", "seg.", "ter.", "qua.", "qui.", "sex.", "sáb."},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"nl": {
+		Date: map[string]string{
+			WidthShort:  "dd-MM-y",
+			WidthMedium: "d MMM y",
+			WidthLong:   "d MMMM y",
+			WidthFull:   "EEEE d MMMM y",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} 'om' {0}",
+			WidthFull:   "{1} 'om' {0}",
+		},
+		Months:     []string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		MonthsAbbr: []string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		Days:       []string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		DaysAbbr:   []string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		DayPeriods: []string{"a.m.", "p.m."},
+	},
+	"ru": {
+		Date: map[string]string{
+			WidthShort:  "dd.MM.y",
+			WidthMedium: "d MMM y 'г'.",
+			WidthLong:   "d MMMM y 'г'.",
+			WidthFull:   "EEEE, d MMMM y 'г'.",
+		},
+		Time: time24,
+		DateTime: map[string]string{
+			WidthShort:  "{1}, {0}",
+			WidthMedium: "{1}, {0}",
+			WidthLong:   "{1}, {0}",
+			WidthFull:   "{1}, {0}",
+		},
+		// Genitive, as used in dates
+		Months:     []string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+		MonthsAbbr: []string{"янв.", "февр.", "мар.", "апр.", "мая", "июн.", "июл.", "авг.", "сент.", "окт.", "нояб.", "дек."},
+		Days:       []string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+		DaysAbbr:   []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+		DayPeriods: []string{"AM", "PM"},
+	},
+	"zh": {
+		Date: map[string]string{
+			WidthShort:  "y/M/d",
+			WidthMedium: "y年M月d日",
+			WidthLong:   "y年M月d日",
+			WidthFull:   "y年M月d日EEEE",
+		},
+		Time: map[string]string{
+			WidthShort:  "HH:mm",
+			WidthMedium: "HH:mm:ss",
+			WidthLong:   "z HH:mm:ss",
+			WidthFull:   "zzzz HH:mm:ss",
+		},
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} {0}",
+			WidthFull:   "{1} {0}",
+		},
+		Months:     []string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+		MonthsAbbr: []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Days:       []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		DaysAbbr:   []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+		DayPeriods: []string{"上午", "下午"},
+	},
+	"ko": {
+		Date: map[string]string{
+			WidthShort:  "yy. M. d.",
+			WidthMedium: "y. M. d.",
+			WidthLong:   "y년 M월 d일",
+			WidthFull:   "y년 M월 d일 EEEE",
+		},
+		Time: map[string]string{
+			WidthShort:  "a h:mm",
+			WidthMedium: "a h:mm:ss",
+			WidthLong:   "a h시 m분 s초 z",
+			WidthFull:   "a h시 m분 s초 zzzz",
+		},
+		DateTime: map[string]string{
+			WidthShort:  "{1} {0}",
+			WidthMedium: "{1} {0}",
+			WidthLong:   "{1} {0}",
+			WidthFull:   "{1} {0}",
+		},
+		Months:     []string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+		MonthsAbbr: []string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+		Days:       []string{"일요일", "월요일", "화요일", "수요일", "목요일", "금요일", "토요일"},
+		DaysAbbr:   []string{"일", "월", "화", "수", "목", "금", "토"},
+		DayPeriods: []string{"오전", "오후"},
+	},
+}
+
+var isoDateConventions = dateConventions{
+	Date: map[string]string{
+		WidthShort:  "y-MM-dd",
+		WidthMedium: "y-MM-dd",
+		WidthLong:   "y-MM-dd",
+		WidthFull:   "y-MM-dd",
+	},
+	Time: time24,
+	DateTime: map[string]string{
+		WidthShort:  "{1} {0}",
+		WidthMedium: "{1} {0}",
+		WidthLong:   "{1} {0}",
+		WidthFull:   "{1} {0}",
+	},
+}
+
+// dateConventionsFor returns the date conventions of a locale, or of its language if it's written in the same
+// script (zh_hant doesn't use the names of zh, which is Simplified Chinese), and whether there were any.
+func dateConventionsFor(locale string) (dateConventions, bool) {
+	if conventions, ok := dateConventionsByLocale[locale]; ok {
+		return conventions, true
+	}
+	language := localeLanguage(locale)
+	if conventions, ok := dateConventionsByLocale[language]; ok && localeScript(locale) == localeScript(language) {
+		return conventions, true
+	}
+	return isoDateConventions, false
+}
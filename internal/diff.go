@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+	// Line numbers before the line, in the old and new content
+	oldLine int
+	newLine int
+}
+
+// UnifiedDiff returns a unified diff from the old to the new content, with three lines of context, or ""
+// if they are equal. Use "/dev/null" as the name of content that doesn't exist.
+func UnifiedDiff(oldName string, newName string, oldContent []byte, newContent []byte) string {
+	ops := diffLines(splitLines(string(oldContent)), splitLines(string(newContent)))
+
+	var sb strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change, and all changes close enough to it to share a hunk
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for next := first + 1; next < len(ops); next++ {
+			if ops[next].kind == ' ' {
+				continue
+			}
+			if next-last-1 > 2*diffContext {
+				break
+			}
+			last = next
+		}
+
+		hunkStart := first - diffContext
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := last + diffContext + 1
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+		}
+		writeHunk(&sb, ops[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(ops[0].oldLine, oldCount), hunkRange(ops[0].newLine, newCount)))
+	for _, op := range ops {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.text)
+		sb.WriteString("\n")
+	}
+}
+
+// hunkRange formats the lines of a hunk like diff -u does, where an empty range starts at the line before it.
+func hunkRange(lineBefore int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", lineBefore)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", lineBefore+1)
+	}
+	return fmt.Sprintf("%d,%d", lineBefore+1, count)
+}
+
+const noNewline = "\n\\ No newline at end of file"
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if !strings.HasSuffix(content, "\n") {
+		// Like in diff -u, a last line without a newline differs from one with, and is marked as such
+		lines[len(lines)-1] += noNewline
+	}
+	return lines
+}
+
+// diffLines returns the operations turning a into b, using the longest common subsequence of their lines.
+func diffLines(a []string, b []string) []diffOp {
+	// Only the changed middle needs the quadratic table, as a regenerated file mostly stays the same
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	oldLine, newLine := 0, 0
+	add := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, oldLine: oldLine, newLine: newLine})
+		if kind != '+' {
+			oldLine++
+		}
+		if kind != '-' {
+			newLine++
+		}
+	}
+
+	for _, line := range a[:prefix] {
+		add(' ', line)
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			add(' ', midA[i])
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			add('-', midA[i])
+			i++
+		default:
+			add('+', midB[j])
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		add(' ', line)
+	}
+	return ops
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "added file",
+			old:      "",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "removed file",
+			old:      "a\n",
+			new:      "",
+			expected: "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:     "context is limited",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n",
+			new:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			expected: "--- old\n+++ new\n@@ -6,3 +6,4 @@\n 6\n 7\n 8\n+9\n",
+		},
+		{
+			name:     "separate hunks",
+			old:      "a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			new:      "A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+		{
+			name:     "close changes share a hunk",
+			old:      "a\n1\n2\n3\n4\n5\n6\nb\n",
+			new:      "A\n1\n2\n3\n4\n5\n6\nB\n",
+			expected: "--- old\n+++ new\n@@ -1,8 +1,8 @@\n-a\n+A\n 1\n 2\n 3\n 4\n 5\n 6\n-b\n+B\n",
+		},
+		{
+			name:     "missing newline at end of file",
+			old:      "a\nb\n",
+			new:      "a\nb",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "added newline at end of file",
+			old:      "a",
+			new:      "a\n",
+			expected: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		{
+			name:     "moved line",
+			old:      "a\nb\nc\n",
+			new:      "b\nc\na\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n-a\n b\n c\n+a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := UnifiedDiff("old", "new", []byte(tt.old), []byte(tt.new))
+			if actual != tt.expected {
+				t.Errorf("Expected:\n%s\nbut got:\n%s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_LargeFile(t *testing.T) {
+	lines := make([]string, 5000)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i%7)
+	}
+	old := strings.Join(lines, "\n") + "\n"
+	lines[2500] = "changed"
+	actual := UnifiedDiff("old", "new", []byte(old), []byte(strings.Join(lines, "\n")+"\n"))
+	if !strings.Contains(actual, "@@ -2498,7 +2498,7 @@\n") || !strings.Contains(actual, "+changed\n") {
+		t.Errorf("Unexpected diff:\n%s", actual)
+	}
+}
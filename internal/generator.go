@@ -8,12 +8,15 @@ import (
 	"strings"
 )
 
+// generatedHeader starts every generated file, and tells generated files apart from hand-written ones.
+const generatedHeader = "// Code generated by simple-translate; DO NOT EDIT."
+
 type Substitution struct {
 	key   string
 	value string
 }
 
-func getKeysSorted(data map[string]TranslateFunc) []string {
+func getKeysSorted[V any](data map[string]V) []string {
 	keys := make([]string, 0, len(data))
 	for key := range data {
 		keys = append(keys, key)
@@ -36,56 +39,110 @@ func genFuncImplementations(sb *strings.Builder, structName string, trFuncs map[
 func GetTranslationImpl(data TomlParseResult, packageName string, verbose bool) ([]byte, error) {
 	var sb strings.Builder
 
-	header := fmt.Sprintf(`
-	  // Code generated by simple-translate; DO NOT EDIT.
-	  package %s
-	`, packageName)
+	header := fmt.Sprintf("%s\npackage %s\n", generatedHeader, packageName)
+
+	if err := genStructImplementation(&sb, localeStructName(data.Locale, nil), "Translation", data.root, data.sections); err != nil {
+		return nil, err
+	}
 
-	// Write all sections first
-	sectionNameToType := make(map[string]string)
-	for sectionKey, sectionData := range data.sections {
-		sectionName := toPrivateName(sectionKey)
-		langName := toPublicName(data.Locale)
-		sectionStructName := fmt.Sprintf("Translation%s_%s", langName, sectionName)
-		sectionNameToType[sectionName] = sectionStructName
+	if usesDates(data) {
+		genDateNames(&sb, conventionsFor(data.Locale))
+	}
 
-		sb.WriteString(fmt.Sprintf("type %s struct{}\n\n", sectionStructName))
-		if err := genFuncImplementations(&sb, sectionStructName, sectionData); err != nil {
-			return nil, err
+	imports := make(map[string]bool)
+	for _, trFunc := range data.allFuncs() {
+		if trFunc.Fallback == "" {
+			imports["fmt"] = true // Used by all but delegating translations
+			break
 		}
 	}
+	addSignatureImports(imports, data.allFuncs())
+	header += genImports(imports)
 
-	// Root translations
-	langName := toPublicName(data.Locale)
-	rootStructName := fmt.Sprintf("Translation%s", langName)
-	sb.WriteString(fmt.Sprintf("type %s struct{", rootStructName))
-	for sectionName, sectionType := range sectionNameToType {
-		sb.WriteString(fmt.Sprintf("\n\t%s %s", sectionName, sectionType))
+	stringContent := header + sb.String()
+	formatted, err := formatCode(stringContent, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatted, nil
+}
+
+// genStructImplementation writes the struct of a locale, or one of its sections, and then the structs of
+// its nested sections. The struct of a section is named after its parent, e.g. TranslationSv_settings_account,
+// and is returned from an accessor on the parent as e.g. Translation_Settings_Account.
+func genStructImplementation(sb *strings.Builder, structName string, interfaceName string, trFuncs map[string]TranslateFunc, sections map[string]translationSection) error {
+	sectionKeys := getKeysSorted(sections)
+
+	sb.WriteString(fmt.Sprintf("type %s struct{", structName))
+	for _, key := range sectionKeys {
+		sb.WriteString(fmt.Sprintf("\n\t%s %s_%s", toPrivateName(key), structName, toPrivateName(key)))
 	}
 	sb.WriteString("\n}\n\n")
 
 	// Accessors
-	for sectionName := range sectionNameToType {
-		methodName := toPublicName(sectionName)
-		interfaceName := fmt.Sprintf("Translation_%s", methodName)
-		sb.WriteString(fmt.Sprintf("func (t *%s) %s() %s {\n", rootStructName, methodName, interfaceName))
-		sb.WriteString(fmt.Sprintf("\treturn &t.%s\n", sectionName))
+	for _, key := range sectionKeys {
+		methodName := toPublicName(key)
+		sb.WriteString(fmt.Sprintf("func (t *%s) %s() %s_%s {\n", structName, methodName, interfaceName, methodName))
+		sb.WriteString(fmt.Sprintf("\treturn &t.%s\n", toPrivateName(key)))
 		sb.WriteString("}\n\n")
 	}
 
-	if err := genFuncImplementations(&sb, rootStructName, data.root); err != nil {
-		return nil, err
+	if err := genFuncImplementations(sb, structName, trFuncs); err != nil {
+		return err
 	}
 
-	header += "\nimport \"fmt\"\n"
+	for _, key := range sectionKeys {
+		section := sections[key]
+		sectionStructName := fmt.Sprintf("%s_%s", structName, toPrivateName(key))
+		sectionInterfaceName := fmt.Sprintf("%s_%s", interfaceName, toPublicName(key))
+		if err := genStructImplementation(sb, sectionStructName, sectionInterfaceName, section.funcs, section.sections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	stringContent := header + sb.String()
-	formatted, err := formatCode(stringContent, verbose)
-	if err != nil {
-		return nil, err
+// localeStructName returns the name of the struct implementing a locale, or a section of it, e.g.
+// TranslationSv or TranslationSv_settings_account.
+func localeStructName(locale string, sectionPath []string) string {
+	name := fmt.Sprintf("Translation%s", toPublicName(locale))
+	for _, key := range sectionPath {
+		name += "_" + toPrivateName(key)
 	}
+	return name
+}
 
-	return formatted, nil
+func addSignatureImports(imports map[string]bool, trFuncs []TranslateFunc) {
+	for _, trFunc := range trFuncs {
+		for _, pkg := range trFunc.SignatureImports() {
+			imports[pkg] = true
+		}
+	}
+}
+
+func genImports(imports map[string]bool) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	// Standard library first, like goimports does
+	var std, other []string
+	for pkg := range imports {
+		if first, _, _ := strings.Cut(pkg, "/"); strings.Contains(first, ".") {
+			other = append(other, strconv.Quote(pkg))
+		} else {
+			std = append(std, strconv.Quote(pkg))
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	groups := make([]string, 0, 2)
+	for _, group := range [][]string{std, other} {
+		if len(group) > 0 {
+			groups = append(groups, strings.Join(group, "\n\t"))
+		}
+	}
+	return fmt.Sprintf("\nimport (\n\t%s\n)\n\n", strings.Join(groups, "\n\n\t"))
 }
 
 func formatCode(src string, verbose bool) ([]byte, error) {
@@ -101,38 +158,38 @@ func formatCode(src string, verbose bool) ([]byte, error) {
 
 func GetBaseTranslation(baseTranslation TomlParseResult, packageName string, verbose bool) ([]byte, error) {
 	var sb strings.Builder
-	sb.WriteString("// Code generated by simple-translate; DO NOT EDIT.\n\n")
+	sb.WriteString(generatedHeader + "\n\n")
 	sb.WriteString(fmt.Sprintf("package %s\n\n", packageName))
 
-	// Sections
-	sectionNameToType := make(map[string]string)
-	for sectionKey, sectionData := range baseTranslation.sections {
-		sectionName := toPublicName(sectionKey)
-		sectionTypeName := fmt.Sprintf("Translation_%s", sectionName)
-		sectionNameToType[sectionName] = sectionTypeName
-
-		sb.WriteString(fmt.Sprintf("type %s interface{\n", sectionTypeName))
-		sortedKeys := getKeysSorted(sectionData)
-		for index, key := range sortedKeys {
-			trFunc := sectionData[key]
-			if index != 0 {
-				sb.WriteString("\n")
-			}
-			sb.WriteString(trFunc.DocString + "\n")
-			sb.WriteString(trFunc.Signature() + "\n")
-		}
-		sb.WriteString("}\n\n")
+	imports := make(map[string]bool)
+	addSignatureImports(imports, baseTranslation.allFuncs())
+	sb.WriteString(genImports(imports))
+
+	genInterface(&sb, "Translation", baseTranslation.root, baseTranslation.sections)
+
+	formatted, err := formatCode(sb.String(), verbose)
+	if err != nil {
+		return nil, err
 	}
 
-	// Root
-	sb.WriteString("type Translation interface{\n")
-	for sectionName, sectionType := range sectionNameToType {
-		sb.WriteString(fmt.Sprintf("\t%s() %s\n", sectionName, sectionType))
+	return formatted, nil
+}
+
+// genInterface writes the interface of a locale, or one of its sections, and then the interfaces of its
+// nested sections.
+func genInterface(sb *strings.Builder, interfaceName string, trFuncs map[string]TranslateFunc, sections map[string]translationSection) {
+	sectionKeys := getKeysSorted(sections)
+
+	sb.WriteString(fmt.Sprintf("type %s interface{\n", interfaceName))
+	for _, key := range sectionKeys {
+		sb.WriteString(fmt.Sprintf("\t%s() %s_%s\n", toPublicName(key), interfaceName, toPublicName(key)))
+	}
+	if len(sectionKeys) > 0 {
+		sb.WriteString("\n")
 	}
-	sb.WriteString("\n")
-	sortedKeys := getKeysSorted(baseTranslation.root)
+	sortedKeys := getKeysSorted(trFuncs)
 	for index, key := range sortedKeys {
-		trFunc := baseTranslation.root[key]
+		trFunc := trFuncs[key]
 		if index != 0 {
 			sb.WriteString("\n")
 		}
@@ -141,74 +198,121 @@ func GetBaseTranslation(baseTranslation TomlParseResult, packageName string, ver
 	}
 	sb.WriteString("}\n\n")
 
-	formatted, err := formatCode(sb.String(), verbose)
-	if err != nil {
-		return nil, err
+	for _, key := range sectionKeys {
+		section := sections[key]
+		genInterface(sb, fmt.Sprintf("%s_%s", interfaceName, toPublicName(key)), section.funcs, section.sections)
 	}
-
-	return formatted, nil
 }
 
-func GetTranslator(allLocales []string, baseLocaleData TomlParseResult, packageName string, verbose bool) ([]byte, error) {
+// GetTranslator generates translator.go. Aliases are by their canonical tags, to the locale they're aliases of.
+func GetTranslator(allLocales []TomlParseResult, baseLocaleData TomlParseResult, aliases map[string]string, packageName string, verbose bool) ([]byte, error) {
 	var sb strings.Builder
-	sb.WriteString("// Code generated by simple-translate; DO NOT EDIT.\n")
+	sb.WriteString(generatedHeader + "\n")
 	sb.WriteString(fmt.Sprintf("package %s\n\n", packageName))
-	sb.WriteString("import \"fmt\"\n\n")
 
+	imports := map[string]bool{"context": true, "fmt": true, "strings": true, "sync": true, "golang.org/x/text/language": true}
+	rootFuncs := make([]TranslateFunc, 0, len(baseLocaleData.root))
+	for _, trFunc := range baseLocaleData.root {
+		rootFuncs = append(rootFuncs, trFunc)
+	}
+	addSignatureImports(imports, rootFuncs)
+	sb.WriteString(genImports(imports))
+
+	// Translations are stateless, so all translators share the same instances
+	missingKeysByTag := make(map[string][]string)
+	sb.WriteString("// Translations of all locales, by their canonical tags\n")
+	sb.WriteString("var translations = map[string]Translation{\n")
+	for _, localeData := range allLocales {
+		sb.WriteString(fmt.Sprintf("\t%s: &%s{},\n", strconv.Quote(localeData.Tag), localeStructName(localeData.Locale, nil)))
+		missingKeysByTag[localeData.Tag] = localeData.MissingKeys()
+	}
+	for _, alias := range getKeysSorted(aliases) {
+		for _, localeData := range allLocales {
+			if localeData.Locale == aliases[alias] {
+				sb.WriteString(fmt.Sprintf("\t%s: &%s{}, // Alias of %s\n", strconv.Quote(alias), localeStructName(localeData.Locale, nil), localeData.Tag))
+				missingKeysByTag[alias] = localeData.MissingKeys()
+			}
+		}
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// For returns the translation of the locale that best matches l, see Match(). Translations are immutable\n")
+	sb.WriteString("// and safe for concurrent use.\n")
+	sb.WriteString("func For(l string) Translation {\n")
+	sb.WriteString("\tlocale, _ := Match(l)\n")
+	sb.WriteString("\treturn translations[locale]\n")
+	sb.WriteString("}\n\n")
+
+	genContext(&sb, baseLocaleData.Tag)
+
+	sb.WriteString("// T is a translator with a language that can be changed. It's safe for concurrent use.\n")
 	sb.WriteString("type T struct {\n")
-	sb.WriteString("\ttranslations map[string]Translation\n")
+	sb.WriteString("\tmu sync.RWMutex\n")
 	sb.WriteString("\tcurrent Translation\n")
 	sb.WriteString("}\n\n")
 
 	sb.WriteString("func NewTranslator() *T {\n")
-	sb.WriteString("\tt := &T{\n")
-	sb.WriteString("\t\ttranslations: make(map[string]Translation),\n")
-	sb.WriteString("\t}\n\n")
-
-	// Register all locales
-	for index, locale := range allLocales {
-		structName := fmt.Sprintf("Translation%s", toPublicName(locale))
-
-		if index == 0 {
-			// Default to base locale
-			baseLocale := toPublicName(baseLocaleData.Locale)
-			sb.WriteString(fmt.Sprintf("\tt.current = &Translation%s{}\n\n", baseLocale))
-		}
+	sb.WriteString(fmt.Sprintf("\treturn &T{current: translations[%s]}\n", strconv.Quote(baseLocaleData.Tag)))
+	sb.WriteString("}\n\n")
 
-		sb.WriteString(fmt.Sprintf("\tt.translations[\"%s\"] = &%s{}\n", locale, structName))
-	}
+	sb.WriteString("// translation returns the translation of the current language.\n")
+	sb.WriteString("func (t *T) translation() Translation {\n")
+	sb.WriteString("\tt.mu.RLock()\n")
+	sb.WriteString("\tdefer t.mu.RUnlock()\n")
+	sb.WriteString("\treturn t.current\n")
+	sb.WriteString("}\n\n")
 
-	sb.WriteString("\n\treturn t\n")
+	sb.WriteString("// canonicalTag returns the canonical form of a BCP 47 tag, e.g. \"zh-Hant-TW\" for \"zh_hant_tw\".\n")
+	sb.WriteString("func canonicalTag(l string) string {\n")
+	sb.WriteString("\ttag, err := language.Parse(strings.ReplaceAll(l, \"_\", \"-\"))\n")
+	sb.WriteString("\tif err != nil {\n")
+	sb.WriteString("\t\treturn l\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn tag.String()\n")
 	sb.WriteString("}\n\n")
 
 	sb.WriteString("func (t *T) SetLanguage(l string) error {\n")
-	sb.WriteString("\ttranslation, exists := t.translations[l]\n")
+	sb.WriteString("\ttranslation, exists := translations[canonicalTag(l)]\n")
 	sb.WriteString("\tif !exists {\n")
 	sb.WriteString("\t\treturn fmt.Errorf(\"language %s not found\", l)\n")
 	sb.WriteString("\t}\n")
+	sb.WriteString("\tt.mu.Lock()\n")
+	sb.WriteString("\tdefer t.mu.Unlock()\n")
 	sb.WriteString("\tt.current = translation\n")
 	sb.WriteString("\treturn nil\n")
 	sb.WriteString("}\n\n")
 
+	sb.WriteString("// SetLanguageFromHeader sets the language that best matches an Accept-Language header, see Match(),\n")
+	sb.WriteString("// and returns it.\n")
+	sb.WriteString("func (t *T) SetLanguageFromHeader(header string) string {\n")
+	sb.WriteString("\tlocale, _ := Match(header)\n")
+	sb.WriteString("\tt.mu.Lock()\n")
+	sb.WriteString("\tdefer t.mu.Unlock()\n")
+	sb.WriteString("\tt.current = translations[locale]\n")
+	sb.WriteString("\treturn locale\n")
+	sb.WriteString("}\n\n")
+
+	genMatcher(&sb, baseLocaleData.Tag, missingKeysByTag)
+	genMissingKeys(&sb, missingKeysByTag)
+
 	// Forwarding methods for accessing sections
-	sectionNameToType := make(map[string]string)
-	for sectionKey := range baseLocaleData.sections {
+	for _, sectionKey := range getKeysSorted(baseLocaleData.sections) {
 		sectionName := toPublicName(sectionKey)
 		sectionType := fmt.Sprintf("Translation_%s", sectionName)
-		sectionNameToType[sectionName] = sectionType
 		sb.WriteString(fmt.Sprintf("func (t *T) %s() %s {\n", sectionName, sectionType))
-		sb.WriteString("\treturn t.current." + sectionName + "()\n")
+		sb.WriteString("\treturn t.translation()." + sectionName + "()\n")
 		sb.WriteString("}\n\n")
 	}
 
 	// Forwarding for root messages
-	for _, tr := range baseLocaleData.root {
+	for _, key := range getKeysSorted(baseLocaleData.root) {
+		tr := baseLocaleData.root[key]
 		sb.WriteString(fmt.Sprintf("func (t *T) %s {\n", tr.Signature()))
 		paramNames := make([]string, len(tr.Params))
 		for i, param := range tr.Params {
 			paramNames[i] = param.Name
 		}
-		sb.WriteString(fmt.Sprintf("\treturn t.current.%s(%s)\n", tr.Name, strings.Join(paramNames, ", ")))
+		sb.WriteString(fmt.Sprintf("\treturn t.translation().%s(%s)\n", tr.Name, strings.Join(paramNames, ", ")))
 		sb.WriteString("}\n\n")
 	}
 
@@ -220,6 +324,147 @@ func GetTranslator(allLocales []string, baseLocaleData TomlParseResult, packageN
 	return formatted, err
 }
 
+type GeneratedFile struct {
+	Name    string
+	Content []byte
+}
+
+// GenerateFiles generates every file of the package, in the same order and with the same content on every run.
+func GenerateFiles(processResult ProcessedLocale, packageName string, verbose bool) ([]GeneratedFile, error) {
+	files := make([]GeneratedFile, 0)
+	allLocales := make([]TomlParseResult, 0)
+	for _, locale := range getKeysSorted(processResult.ParsedFuncsByLocale) {
+		tomlData := processResult.ParsedFuncsByLocale[locale]
+		content, err := GetTranslationImpl(tomlData, packageName, verbose)
+		if err != nil {
+			return nil, fmt.Errorf("generating translation implementation for %s: %w", locale, err)
+		}
+		files = append(files, GeneratedFile{Name: tomlData.Locale + ".go", Content: content})
+		allLocales = append(allLocales, tomlData)
+	}
+
+	baseLocaleData := processResult.ParsedFuncsByLocale[processResult.BaseLocale]
+	generators := []struct {
+		name     string
+		what     string
+		generate func() ([]byte, error)
+	}{
+		{"base.go", "base translation interface", func() ([]byte, error) { return GetBaseTranslation(baseLocaleData, packageName, verbose) }},
+		{"format.go", "format helpers", func() ([]byte, error) { return GetFormatHelpers(packageName, verbose) }},
+		{"lookup.go", "lookup", func() ([]byte, error) { return GetLookup(baseLocaleData, packageName, verbose) }},
+		{"messages.go", "messages", func() ([]byte, error) { return GetMessages(baseLocaleData, packageName, verbose) }},
+		{"middleware.go", "middleware", func() ([]byte, error) { return GetMiddleware(packageName, verbose) }},
+		{"translator.go", "translator", func() ([]byte, error) {
+			return GetTranslator(allLocales, baseLocaleData, processResult.Aliases, packageName, verbose)
+		}},
+	}
+	for _, generator := range generators {
+		content, err := generator.generate()
+		if err != nil {
+			return nil, fmt.Errorf("generating %s: %w", generator.what, err)
+		}
+		files = append(files, GeneratedFile{Name: generator.name, Content: content})
+	}
+
+	return files, nil
+}
+
+// genContext writes WithLocale() and FromContext(), which carry the locale of e.g. a request through a context.
+func genContext(sb *strings.Builder, baseTag string) {
+	sb.WriteString("type localeContextKey struct{}\n\n")
+
+	sb.WriteString("// WithLocale returns a copy of ctx carrying the locale that best matches l, see Match().\n")
+	sb.WriteString("func WithLocale(ctx context.Context, l string) context.Context {\n")
+	sb.WriteString("\tlocale, _ := Match(l)\n")
+	sb.WriteString("\treturn context.WithValue(ctx, localeContextKey{}, locale)\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// LocaleFromContext returns the locale carried by ctx, or the base locale if there is none.\n")
+	sb.WriteString("func LocaleFromContext(ctx context.Context) string {\n")
+	sb.WriteString("\tif locale, ok := ctx.Value(localeContextKey{}).(string); ok {\n")
+	sb.WriteString("\t\treturn locale\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString(fmt.Sprintf("\treturn %s\n", strconv.Quote(baseTag)))
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// FromContext returns the translation of the locale carried by ctx, or of the base locale if there is none.\n")
+	sb.WriteString("func FromContext(ctx context.Context) Translation {\n")
+	sb.WriteString("\treturn translations[LocaleFromContext(ctx)]\n")
+	sb.WriteString("}\n\n")
+}
+
+// genMatcher writes Match(), which negotiates the best locale for an Accept-Language header. The base
+// locale goes first, since that's what the matcher falls back to.
+func genMatcher(sb *strings.Builder, baseTag string, tagSet map[string][]string) {
+	tags := []string{baseTag}
+	for tag := range tagSet {
+		if tag != baseTag {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags[1:])
+
+	sb.WriteString("var supportedLocales = []string{")
+	for index, tag := range tags {
+		if index != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.Quote(tag))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("var matcher = func() language.Matcher {\n")
+	sb.WriteString("\ttags := make([]language.Tag, len(supportedLocales))\n")
+	sb.WriteString("\tfor i, locale := range supportedLocales {\n")
+	sb.WriteString("\t\ttags[i] = language.MustParse(locale)\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn language.NewMatcher(tags)\n")
+	sb.WriteString("}()\n\n")
+
+	sb.WriteString("// Match returns the locale that best matches an Accept-Language header, like \"sv-SE,sv;q=0.9,en;q=0.8\",\n")
+	sb.WriteString("// or a single locale like \"en-GB\", and how confident the match is. The base locale is returned with\n")
+	sb.WriteString("// language.No when nothing matches.\n")
+	sb.WriteString("func Match(header string) (string, language.Confidence) {\n")
+	sb.WriteString("\ttags, _, err := language.ParseAcceptLanguage(strings.ReplaceAll(header, \"_\", \"-\"))\n")
+	sb.WriteString("\tif err != nil || len(tags) == 0 {\n")
+	sb.WriteString("\t\treturn supportedLocales[0], language.No\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\t_, index, confidence := matcher.Match(tags...)\n")
+	sb.WriteString("\tif confidence == language.No {\n")
+	sb.WriteString("\t\treturn supportedLocales[0], language.No\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn supportedLocales[index], confidence\n")
+	sb.WriteString("}\n\n")
+}
+
+// genMissingKeys writes MissingKeys(), which reports the translations that fall back to the base locale
+// because they're missing (see -allow-missing).
+func genMissingKeys(sb *strings.Builder, missingKeysByTag map[string][]string) {
+	tags := make([]string, 0, len(missingKeysByTag))
+	for tag, keys := range missingKeysByTag {
+		if len(keys) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	sb.WriteString("var missingKeys = map[string][]string{\n")
+	for _, tag := range tags {
+		quoted := make([]string, len(missingKeysByTag[tag]))
+		for i, key := range missingKeysByTag[tag] {
+			quoted[i] = strconv.Quote(key)
+		}
+		sb.WriteString(fmt.Sprintf("\t%s: {%s},\n", strconv.Quote(tag), strings.Join(quoted, ", ")))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// MissingKeys returns the keys of the translations that are missing in a locale, e.g. \"menu.title\".\n")
+	sb.WriteString("// These fall back to the base locale.\n")
+	sb.WriteString("func MissingKeys(locale string) []string {\n")
+	sb.WriteString("\treturn append([]string(nil), missingKeys[canonicalTag(locale)]...)\n")
+	sb.WriteString("}\n\n")
+}
+
 func toPublicName(name string) string {
 	parts := strings.Split(name, "_")
 	var result []string
@@ -254,3 +499,729 @@ func genSprintfReturn(sb *strings.Builder, value string, fmtArgs []string) {
 	}
 	sb.WriteString(fmt.Sprintf("\treturn fmt.Sprintf(%s%s)\n", fmtString, argPart))
 }
+
+// genPluralSwitch declares varName and assigns it the form of the plural block matching operand.
+func genPluralSwitch(sb *strings.Builder, varName string, operand string, conventions localeConventions, block pluralBlock) {
+	rule := conventions.Plural
+	countArg := genNumberArg(operand, "int", conventions.Numbers)
+	if len(rule) == 1 && len(block.Exact) == 0 {
+		sb.WriteString(fmt.Sprintf("\t%s := %s\n", varName, block.formExpr(block.Forms[0], countArg)))
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("\tvar %s string\n", varName))
+	sb.WriteString("\tswitch {\n")
+	for _, exact := range block.Exact {
+		sb.WriteString(fmt.Sprintf("\tcase %s == %d:\n", operand, exact.Value))
+		sb.WriteString(fmt.Sprintf("\t\t%s = %s\n", varName, block.formExpr(exact.Form, countArg)))
+	}
+	for i, form := range block.Forms {
+		if i == len(rule)-1 {
+			sb.WriteString("\tdefault:\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("\tcase %s:\n", rule.condition(i, operand)))
+		}
+		sb.WriteString(fmt.Sprintf("\t\t%s = %s\n", varName, block.formExpr(form, countArg)))
+	}
+	sb.WriteString("\t}\n")
+}
+
+// genSelectSwitch declares varName and assigns it the text of the option matching the parameter.
+func genSelectSwitch(sb *strings.Builder, varName string, block selectBlock) {
+	sb.WriteString(fmt.Sprintf("\tvar %s string\n", varName))
+	sb.WriteString(fmt.Sprintf("\tswitch %s {\n", block.Param))
+	otherText := ""
+	for _, option := range block.Options {
+		if option.Key == selectOther {
+			otherText = option.Text
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\tcase %s:\n", strconv.Quote(option.Key)))
+		sb.WriteString(fmt.Sprintf("\t\t%s = %s\n", varName, strconv.Quote(option.Text)))
+	}
+	sb.WriteString("\tdefault:\n")
+	sb.WriteString(fmt.Sprintf("\t\t%s = %s\n", varName, strconv.Quote(otherText)))
+	sb.WriteString("\t}\n")
+}
+
+// genNumberArg returns the expression formatting a number parameter with the separators of the
+// locale, or an empty string if the parameter isn't a number.
+func genNumberArg(name string, paramType string, symbols numberSymbols) string {
+	switch paramType {
+	case "int":
+		return fmt.Sprintf("formatInt(%s, %s, %d, %d)", name, strconv.Quote(symbols.Group), symbols.MinGrouping, symbols.secondaryGrouping())
+	case "float64":
+		return fmt.Sprintf("formatFloat(%s, %s, %s, %d, %d)", name, strconv.Quote(symbols.Decimal), strconv.Quote(symbols.Group), symbols.MinGrouping, symbols.secondaryGrouping())
+	}
+	return ""
+}
+
+// genTimeArg returns the expression formatting a time parameter with the patterns of the locale.
+func genTimeArg(name string, style string, width string, conventions localeConventions) string {
+	if style != "date" && style != "time" {
+		style = "datetime"
+	}
+	if width == "" {
+		width = WidthMedium
+	}
+	pattern := conventions.Dates.datePattern(style, width)
+	return fmt.Sprintf("formatTime(%s, %s, &%s)", name, strconv.Quote(pattern), conventions.dateNamesVar())
+}
+
+func usesDates(data TomlParseResult) bool {
+	for _, trFunc := range data.allFuncs() {
+		if trFunc.usesDates() {
+			return true
+		}
+	}
+	return false
+}
+
+func genDateNames(sb *strings.Builder, conventions localeConventions) {
+	quoteAll := func(names []string) string {
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = strconv.Quote(name)
+		}
+		return strings.Join(quoted, ", ")
+	}
+	dates := conventions.Dates
+	sb.WriteString(fmt.Sprintf("var %s = dateNames{\n", conventions.dateNamesVar()))
+	sb.WriteString(fmt.Sprintf("\tmonths: []string{%s},\n", quoteAll(dates.Months)))
+	sb.WriteString(fmt.Sprintf("\tmonthsAbbr: []string{%s},\n", quoteAll(dates.MonthsAbbr)))
+	sb.WriteString(fmt.Sprintf("\tdays: []string{%s},\n", quoteAll(dates.Days)))
+	sb.WriteString(fmt.Sprintf("\tdaysAbbr: []string{%s},\n", quoteAll(dates.DaysAbbr)))
+	sb.WriteString(fmt.Sprintf("\tdayPeriods: []string{%s},\n", quoteAll(dates.DayPeriods)))
+	sb.WriteString("}\n\n")
+}
+
+// GetFormatHelpers returns the helpers that generated translations use to format values.
+func GetFormatHelpers(packageName string, verbose bool) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader + "\n\n")
+	sb.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	sb.WriteString(formatHelpers)
+
+	formatted, err := formatCode(sb.String(), verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatted, nil
+}
+
+const formatHelpers = `import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatInt formats n with group separators, as long as there are at least minGrouping digits
+// in front of the first separator. The last group has three digits, and the others secondary digits.
+func formatInt(n int, group string, minGrouping int, secondary int) string {
+	return groupDigits(strconv.Itoa(n), group, minGrouping, secondary)
+}
+
+func formatFloat(f float64, decimal string, group string, minGrouping int, secondary int) string {
+	// Written like CLDR does, since they have no digits to group
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "∞"
+	case math.IsInf(f, -1):
+		return "-∞"
+	}
+	digits := strconv.FormatFloat(f, 'f', -1, 64)
+	integer, fraction, hasFraction := strings.Cut(digits, ".")
+	formatted := groupDigits(integer, group, minGrouping, secondary)
+	if hasFraction {
+		formatted += decimal + fraction
+	}
+	return formatted
+}
+
+func groupDigits(digits string, group string, minGrouping int, secondary int) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) < 4+minGrouping-1 {
+		return sign + digits
+	}
+
+	groups := make([]string, 0, len(digits)/secondary+1)
+	for size := 3; len(digits) > size; size = secondary {
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+	groups = append(groups, digits)
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	return sign + strings.Join(groups, group)
+}
+
+// dateNames are the month and day names of a locale. Formats fall back to numbers and English names
+// when names are missing.
+type dateNames struct {
+	months     []string
+	monthsAbbr []string
+	days       []string // Starting with Sunday
+	daysAbbr   []string
+	dayPeriods []string // AM and PM
+}
+
+// formatTime formats t with a CLDR date pattern, e.g. "d MMMM y HH:mm". Text in single quotes is
+// written as is. Long time zone names (zzzz) are written as the name of the location.
+func formatTime(t time.Time, pattern string, names *dateNames) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c == '\'' {
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			switch {
+			case end == 0: // '' is an apostrophe
+				sb.WriteByte('\'')
+			case end < 0:
+				sb.WriteString(pattern[i+1:])
+				end = len(pattern) - i - 1
+			default:
+				sb.WriteString(pattern[i+1 : i+1+end])
+			}
+			i += end + 2
+			continue
+		}
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			sb.WriteByte(c)
+			i++
+			continue
+		}
+
+		n := 1
+		for i+n < len(pattern) && pattern[i+n] == c {
+			n++
+		}
+		i += n
+
+		switch c {
+		case 'y':
+			if n == 2 {
+				sb.WriteString(padInt(t.Year()%100, 2))
+			} else {
+				sb.WriteString(padInt(t.Year(), n))
+			}
+		case 'M', 'L':
+			month := int(t.Month()) - 1
+			if n >= 4 && len(names.months) == 12 {
+				sb.WriteString(names.months[month])
+			} else if n >= 3 && len(names.monthsAbbr) == 12 {
+				sb.WriteString(names.monthsAbbr[month])
+			} else if n >= 3 {
+				sb.WriteString(t.Month().String()[:3])
+			} else {
+				sb.WriteString(padInt(month+1, n))
+			}
+		case 'd':
+			sb.WriteString(padInt(t.Day(), n))
+		case 'E':
+			day := int(t.Weekday())
+			if n >= 4 && len(names.days) == 7 {
+				sb.WriteString(names.days[day])
+			} else if len(names.daysAbbr) == 7 {
+				sb.WriteString(names.daysAbbr[day])
+			} else {
+				sb.WriteString(t.Weekday().String()[:3])
+			}
+		case 'a':
+			period := 0
+			if t.Hour() >= 12 {
+				period = 1
+			}
+			if len(names.dayPeriods) == 2 {
+				sb.WriteString(names.dayPeriods[period])
+			} else {
+				sb.WriteString([]string{"AM", "PM"}[period])
+			}
+		case 'h':
+			hour := t.Hour() % 12
+			if hour == 0 {
+				hour = 12
+			}
+			sb.WriteString(padInt(hour, n))
+		case 'H':
+			sb.WriteString(padInt(t.Hour(), n))
+		case 'm':
+			sb.WriteString(padInt(t.Minute(), n))
+		case 's':
+			sb.WriteString(padInt(t.Second(), n))
+		case 'z':
+			if n >= 4 {
+				sb.WriteString(t.Location().String())
+			} else {
+				sb.WriteString(t.Format("MST"))
+			}
+		default:
+			sb.WriteString(pattern[i-n : i])
+		}
+	}
+	return sb.String()
+}
+
+func padInt(value int, width int) string {
+	digits := strconv.Itoa(value)
+	for len(digits) < width {
+		digits = "0" + digits
+	}
+	return digits
+}
+`
+
+func GetMiddleware(packageName string, verbose bool) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader + "\n\n")
+	sb.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	sb.WriteString(middleware)
+
+	formatted, err := formatCode(sb.String(), verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatted, nil
+}
+
+const middleware = `import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// LocaleSource is where Middleware() looks for the locale of a request.
+type LocaleSource int
+
+const (
+	SourcePath   LocaleSource = iota // First segment of the URL path, e.g. "/sv/about"
+	SourceQuery                      // Query parameter, e.g. "?lang=sv"
+	SourceCookie                     // Cookie, e.g. "lang=sv"
+	SourceHeader                     // Accept-Language header
+)
+
+type MiddlewareOptions struct {
+	// Where to look for the locale, in order. Defaults to query, cookie and then header.
+	Sources []LocaleSource
+	// Name of the query parameter for SourceQuery. Defaults to "lang".
+	QueryParam string
+	// Name of the cookie for SourceCookie. Defaults to "lang".
+	CookieName string
+	// Whether to set the Content-Language header of responses to the locale.
+	SetContentLanguage bool
+}
+
+// Middleware finds the locale of each request, and stores it in the context of the request. Handlers get
+// the translation with FromContext(r.Context()). Requests without a supported locale get the base locale.
+func Middleware(options MiddlewareOptions) func(http.Handler) http.Handler {
+	if len(options.Sources) == 0 {
+		options.Sources = []LocaleSource{SourceQuery, SourceCookie, SourceHeader}
+	}
+	if options.QueryParam == "" {
+		options.QueryParam = "lang"
+	}
+	if options.CookieName == "" {
+		options.CookieName = "lang"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := requestLocale(r, options)
+			if options.SetContentLanguage {
+				w.Header().Set("Content-Language", locale)
+			}
+			next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), locale)))
+		})
+	}
+}
+
+// requestLocale returns the supported locale of the first source that has one.
+func requestLocale(r *http.Request, options MiddlewareOptions) string {
+	for _, source := range options.Sources {
+		value := ""
+		switch source {
+		case SourcePath:
+			value, _, _ = strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		case SourceQuery:
+			value = r.URL.Query().Get(options.QueryParam)
+		case SourceCookie:
+			if cookie, err := r.Cookie(options.CookieName); err == nil {
+				value = cookie.Value
+			}
+		case SourceHeader:
+			value = r.Header.Get("Accept-Language")
+		}
+		if value == "" {
+			continue
+		}
+		if locale, confidence := Match(value); confidence != language.No {
+			return locale
+		}
+	}
+	return supportedLocales[0]
+}
+`
+
+// Helpers in lookup.go that convert arguments to the type of a parameter
+var lookupArgFuncs = map[string]string{
+	"string":       "stringArg",
+	"int":          "intArg",
+	"float64":      "floatArg",
+	"time.Time":    "timeArg",
+	"fmt.Stringer": "stringerArg",
+	"any":          "anyArg",
+}
+
+// GetLookup generates Lookup(), which looks up translations by their dotted keys (e.g. "menu.title") with
+// arguments by name, for keys that are only known at runtime.
+func GetLookup(baseLocaleData TomlParseResult, packageName string, verbose bool) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader + "\n\n")
+	sb.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	sb.WriteString(lookupHelpers)
+
+	sb.WriteString("// Translations by their dotted keys, with the names of their parameters\n")
+	sb.WriteString("var lookups = map[string]struct {\n")
+	sb.WriteString("\tparams    []string\n")
+	sb.WriteString("\ttranslate func(translation Translation, key string, args map[string]any) (string, error)\n")
+	sb.WriteString("}{\n")
+	genLookups(&sb, "", "translation", baseLocaleData.root, baseLocaleData.sections)
+	sb.WriteString("}\n")
+
+	formatted, err := formatCode(sb.String(), verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatted, nil
+}
+
+// genLookups writes the entries of the lookup table for the translations of a section, and its nested sections.
+// accessor is the expression returning the section, e.g. translation.Settings().Account().
+func genLookups(sb *strings.Builder, keyPrefix string, accessor string, trFuncs map[string]TranslateFunc, sections map[string]translationSection) {
+	for _, key := range getKeysSorted(trFuncs) {
+		trFunc := trFuncs[key]
+		params := make([]string, len(trFunc.Params))
+		args := make([]string, len(trFunc.Params))
+		for i, param := range trFunc.Params {
+			params[i] = strconv.Quote(param.Name)
+			args[i] = fmt.Sprintf("arg%d", i)
+		}
+
+		sb.WriteString(fmt.Sprintf("\t%s: {\n", strconv.Quote(keyPrefix+key)))
+		sb.WriteString(fmt.Sprintf("\t\tparams: []string{%s},\n", strings.Join(params, ", ")))
+		sb.WriteString("\t\ttranslate: func(translation Translation, key string, args map[string]any) (string, error) {\n")
+		for i, param := range trFunc.Params {
+			sb.WriteString(fmt.Sprintf("\t\t\t%s, err := %s(key, args, %s)\n", args[i], lookupArgFuncs[param.Type], strconv.Quote(param.Name)))
+			sb.WriteString("\t\t\tif err != nil {\n")
+			sb.WriteString("\t\t\t\treturn \"\", err\n")
+			sb.WriteString("\t\t\t}\n")
+		}
+		sb.WriteString(fmt.Sprintf("\t\t\treturn %s.%s(%s), nil\n", accessor, trFunc.Name, strings.Join(args, ", ")))
+		sb.WriteString("\t\t},\n")
+		sb.WriteString("\t},\n")
+	}
+
+	for _, key := range getKeysSorted(sections) {
+		section := sections[key]
+		genLookups(sb, keyPrefix+key+".", fmt.Sprintf("%s.%s()", accessor, toPublicName(key)), section.funcs, section.sections)
+	}
+}
+
+const lookupHelpers = `import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Lookup returns the translation of a dotted key, like "menu.title", with arguments by parameter name. This
+// is for keys that are only known at runtime, e.g. when they are stored in a database. Prefer the typed
+// methods otherwise, since mistakes in keys and arguments are only found when the translation is looked up.
+func Lookup(translation Translation, key string, args map[string]any) (string, error) {
+	lookup, exists := lookups[key]
+	if !exists {
+		return "", fmt.Errorf("unknown translation '%s'", key)
+	}
+	for name := range args {
+		if !containsString(lookup.params, name) {
+			return "", fmt.Errorf("unexpected argument '%s' for '%s' (expected %v)", name, key, lookup.params)
+		}
+	}
+	return lookup.translate(translation, key, args)
+}
+
+// Lookup looks up a translation in the current language, see Lookup().
+func (t *T) Lookup(key string, args map[string]any) (string, error) {
+	return Lookup(t.translation(), key, args)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupArg(key string, args map[string]any, name string) (any, error) {
+	value, exists := args[name]
+	if !exists {
+		return nil, fmt.Errorf("missing argument '%s' for '%s'", name, key)
+	}
+	return value, nil
+}
+
+func argTypeError(key string, name string, expected string, value any) error {
+	return fmt.Errorf("argument '%s' for '%s' must be %s, but was %T", name, key, expected, value)
+}
+
+func stringArg(key string, args map[string]any, name string) (string, error) {
+	value, err := lookupArg(key, args, name)
+	if err != nil {
+		return "", err
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return "", argTypeError(key, name, "string", value)
+}
+
+// intArg accepts any integer, and floats without fractions since that's what numbers decoded from JSON are.
+func intArg(key string, args map[string]any, name string) (int, error) {
+	value, err := lookupArg(key, args, name)
+	if err != nil {
+		return 0, err
+	}
+	switch n := value.(type) {
+	case int:
+		return n, nil
+	case int8:
+		return int(n), nil
+	case int16:
+		return int(n), nil
+	case int32:
+		return int(n), nil
+	case int64:
+		if n >= math.MinInt && n <= math.MaxInt {
+			return int(n), nil
+		}
+		return 0, argRangeError(key, name, value)
+	case uint:
+		return uintArg(key, name, uint64(n))
+	case uint8:
+		return int(n), nil
+	case uint16:
+		return int(n), nil
+	case uint32:
+		return uintArg(key, name, uint64(n))
+	case uint64:
+		return uintArg(key, name, n)
+	case float32:
+		return floatIntArg(key, name, float64(n), value)
+	case float64:
+		return floatIntArg(key, name, n, value)
+	}
+	return 0, argTypeError(key, name, "int", value)
+}
+
+func uintArg(key string, name string, n uint64) (int, error) {
+	if n > math.MaxInt {
+		return 0, argRangeError(key, name, n)
+	}
+	return int(n), nil
+}
+
+// floatIntArg accepts floats without fractions that fit in an int, which rules out NaN and infinities.
+func floatIntArg(key string, name string, f float64, value any) (int, error) {
+	if f != math.Trunc(f) {
+		return 0, argTypeError(key, name, "int", value)
+	}
+	if f < math.MinInt || f >= math.MaxInt {
+		return 0, argRangeError(key, name, value)
+	}
+	return int(f), nil
+}
+
+func argRangeError(key string, name string, value any) error {
+	return fmt.Errorf("argument '%s' for '%s' must fit in an int, but was %v", name, key, value)
+}
+
+func floatArg(key string, args map[string]any, name string) (float64, error) {
+	value, err := lookupArg(key, args, name)
+	if err != nil {
+		return 0, err
+	}
+	switch n := value.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	}
+	if n, err := intArg(key, args, name); err == nil {
+		return float64(n), nil
+	}
+	return 0, argTypeError(key, name, "float64", value)
+}
+
+func timeArg(key string, args map[string]any, name string) (time.Time, error) {
+	value, err := lookupArg(key, args, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+	return time.Time{}, argTypeError(key, name, "time.Time", value)
+}
+
+func stringerArg(key string, args map[string]any, name string) (fmt.Stringer, error) {
+	value, err := lookupArg(key, args, name)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := value.(fmt.Stringer); ok || value == nil {
+		// nil is a fmt.Stringer too, and can be passed to the typed methods
+		return s, nil
+	}
+	return nil, argTypeError(key, name, "fmt.Stringer", value)
+}
+
+func anyArg(key string, args map[string]any, name string) (any, error) {
+	return lookupArg(key, args, name)
+}
+
+`
+
+// GetMessages generates Msg, which creates messages that are translated later, once the locale is known.
+func GetMessages(baseLocaleData TomlParseResult, packageName string, verbose bool) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader + "\n\n")
+	sb.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+
+	imports := make(map[string]bool)
+	addSignatureImports(imports, baseLocaleData.allFuncs())
+	sb.WriteString(genImports(imports))
+
+	sb.WriteString(messageType)
+	sb.WriteString("// In returns the message translated by translation, e.g. In(For(\"sv\")) or In(FromContext(ctx)). A nil\n")
+	sb.WriteString("// translation is the base locale, which is also used if the message can't be translated by translation.\n")
+	sb.WriteString("// Only the zero Message, which has no translation at all, returns its key, which is empty.\n")
+	sb.WriteString("func (m Message) In(translation Translation) string {\n")
+	sb.WriteString("\tif translation != nil {\n")
+	sb.WriteString("\t\tif message, err := Lookup(translation, m.key, m.args); err == nil {\n")
+	sb.WriteString("\t\t\treturn message\n")
+	sb.WriteString("\t\t}\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString(fmt.Sprintf("\tif message, err := Lookup(translations[%s], m.key, m.args); err == nil {\n", strconv.Quote(baseLocaleData.Tag)))
+	sb.WriteString("\t\treturn message\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn m.key\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// String returns the message in the base locale.\n")
+	sb.WriteString("func (m Message) String() string {\n")
+	sb.WriteString("\treturn m.In(nil)\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("// Msg creates messages with the same methods as Translation, e.g. Msg.Menu.Title(), which are\n")
+	sb.WriteString("// translated later with Message.In().\n")
+	sb.WriteString("var Msg = msg{}\n\n")
+	genMessageType(&sb, "msg", "", baseLocaleData.root, baseLocaleData.sections)
+
+	formatted, err := formatCode(sb.String(), verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatted, nil
+}
+
+// genMessageType writes the type creating the messages of a section, e.g. msg_settings_account for
+// Msg.Settings.Account, and then the types of its nested sections.
+func genMessageType(sb *strings.Builder, typeName string, keyPrefix string, trFuncs map[string]TranslateFunc, sections map[string]translationSection) {
+	sectionKeys := getKeysSorted(sections)
+
+	sb.WriteString(fmt.Sprintf("type %s struct{", typeName))
+	for _, key := range sectionKeys {
+		sb.WriteString(fmt.Sprintf("\n\t%s %s_%s", toPublicName(key), typeName, toPrivateName(key)))
+	}
+	sb.WriteString("\n}\n\n")
+
+	for _, key := range getKeysSorted(trFuncs) {
+		trFunc := trFuncs[key]
+		args := make([]string, len(trFunc.Params))
+		for i, param := range trFunc.Params {
+			args[i] = fmt.Sprintf("%s: %s", strconv.Quote(param.Name), param.Name)
+		}
+		sb.WriteString(fmt.Sprintf("func (%s) %s(%s) Message {\n", typeName, trFunc.Name, trFunc.ParamsList()))
+		sb.WriteString(fmt.Sprintf("\treturn Message{key: %s, args: map[string]any{%s}}\n", strconv.Quote(keyPrefix+key), strings.Join(args, ", ")))
+		sb.WriteString("}\n\n")
+	}
+
+	for _, key := range sectionKeys {
+		section := sections[key]
+		genMessageType(sb, fmt.Sprintf("%s_%s", typeName, toPrivateName(key)), keyPrefix+key+".", section.funcs, section.sections)
+	}
+}
+
+const messageType = `// Message is a translation with its arguments, which is translated once the locale is known. This lets
+// code without a translator return translatable messages.
+type Message struct {
+	key  string
+	args map[string]any
+}
+
+// Key returns the dotted key of the translation, e.g. "menu.title".
+func (m Message) Key() string {
+	return m.key
+}
+
+// Args returns the arguments of the message by parameter name.
+func (m Message) Args() map[string]any {
+	args := make(map[string]any, len(m.args))
+	for name, value := range m.args {
+		args[name] = value
+	}
+	return args
+}
+
+// Error is an error with a message that can be translated for users, e.g. by API handlers. It can wrap
+// the underlying error, which is meant for developers.
+type Error struct {
+	Message Message
+	Err     error
+}
+
+// NewError returns an error with a translatable message, which wraps err (which can be nil).
+func NewError(message Message, err error) *Error {
+	return &Error{Message: message, Err: err}
+}
+
+// Error returns the message in the base locale, followed by the wrapped error.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message.String()
+	}
+	return e.Message.String() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Localize returns the message translated by translation, without the wrapped error.
+func (e *Error) Localize(translation Translation) string {
+	return e.Message.In(translation)
+}
+
+`
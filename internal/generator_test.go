@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+func generateTestdata(t *testing.T) []GeneratedFile {
+	t.Helper()
+	processed, err := ProcessTomlDir(filepath.Join("testdata", "toml"), ProcessOptions{BaseLocale: "sv", AllowMissing: []string{"de"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	files, err := GenerateFiles(processed, "i18n", false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return files
+}
+
+func TestGenerateFiles_Golden(t *testing.T) {
+	for _, file := range generateTestdata(t) {
+		t.Run(file.Name, func(t *testing.T) {
+			golden := filepath.Join("testdata", "golden", file.Name+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, file.Content, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file, run with -update: %v", err)
+			}
+			if !bytes.Equal(expected, file.Content) {
+				t.Errorf("Generated %s differs from %s, run with -update if the change is intended", file.Name, golden)
+			}
+		})
+	}
+}
+
+func TestGenerateFiles_Deterministic(t *testing.T) {
+	first := generateTestdata(t)
+	// Map iteration order is random, so repeated runs would catch any unordered emission
+	for i := 0; i < 20; i++ {
+		files := generateTestdata(t)
+		if len(files) != len(first) {
+			t.Fatalf("Expected %d files, but got %d", len(first), len(files))
+		}
+		for j, file := range files {
+			if file.Name != first[j].Name || !bytes.Equal(file.Content, first[j].Content) {
+				t.Fatalf("Expected %s to be generated the same way on every run", first[j].Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// localeConventions bundles the CLDR data that messages of a locale are generated with.
+type localeConventions struct {
+	Name    string // Name of the locale as used in generated identifiers
+	Plural  pluralRule
+	Numbers numberSymbols
+	Dates   dateConventions
+}
+
+func conventionsFor(locale string) localeConventions {
+	dates, _ := dateConventionsFor(locale)
+	return localeConventions{
+		Name:    toPublicName(locale),
+		Plural:  pluralRuleFor(locale),
+		Numbers: numberSymbolsFor(locale),
+		Dates:   dates,
+	}
+}
+
+// dateNamesVar is the name of the generated variable holding the month and day names of a locale.
+func (c localeConventions) dateNamesVar() string {
+	return "dateNames" + c.Name
+}
+
+// canonicalLocale parses a BCP 47 tag, separated by '-' or '_', into its canonical form (e.g. "zh-Hant-TW"),
+// and the form used for file names and identifiers (e.g. "zh_hant_tw").
+func canonicalLocale(name string) (string, string, error) {
+	tag, err := language.Parse(strings.ReplaceAll(name, "_", "-"))
+	if err != nil {
+		return "", "", fmt.Errorf("'%s' is not a BCP 47 locale: %w", name, err)
+	}
+	canonical := tag.String()
+	return canonical, strings.ToLower(strings.ReplaceAll(canonical, "-", "_")), nil
+}
+
+func localeLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "_")
+	return language
+}
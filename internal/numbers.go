@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// numberSymbols are the CLDR symbols used to format numbers in a locale.
+type numberSymbols struct {
+	Decimal string
+	Group   string
+	// Minimum number of digits in front of the first group separator, e.g. 2 for Spanish where
+	// 1000 is written without separator but 10 000 isn't.
+	MinGrouping int
+	// Size of the groups in front of the last one, e.g. 2 in India where a million is 10,00,000. 0 for 3.
+	SecondaryGrouping int
+}
+
+func (s numberSymbols) secondaryGrouping() int {
+	if s.SecondaryGrouping == 0 {
+		return 3
+	}
+	return s.SecondaryGrouping
+}
+
+const (
+	nbsp       = "\u00a0"
+	narrowNbsp = "\u202f"
+)
+
+var (
+	symbolsPointComma = numberSymbols{Decimal: ".", Group: ",", MinGrouping: 1}
+	symbolsCommaPoint = numberSymbols{Decimal: ",", Group: ".", MinGrouping: 1}
+	symbolsCommaSpace = numberSymbols{Decimal: ",", Group: nbsp, MinGrouping: 1}
+)
+
+// Number symbols keyed by locale or language, derived from the CLDR data for the latin numbering system.
+var numberSymbolsByLocale = map[string]numberSymbols{
+	"ar": symbolsPointComma, "en": symbolsPointComma, "fil": symbolsPointComma, "he": symbolsPointComma,
+	"ja": symbolsPointComma, "ko": symbolsPointComma, "th": symbolsPointComma, "yue": symbolsPointComma,
+	"zh": symbolsPointComma, "ga": symbolsPointComma, "mt": symbolsPointComma,
+
+	"da": symbolsCommaPoint, "de": symbolsCommaPoint, "el": symbolsCommaPoint, "id": symbolsCommaPoint,
+	"it": symbolsCommaPoint, "nl": symbolsCommaPoint, "pt": symbolsCommaPoint, "ro": symbolsCommaPoint,
+	"tr": symbolsCommaPoint, "vi": symbolsCommaPoint, "hr": symbolsCommaPoint, "sr": symbolsCommaPoint,
+	"sl": symbolsCommaPoint, "bs": symbolsCommaPoint, "ca": symbolsCommaPoint, "eu": symbolsCommaPoint,
+	"gl": symbolsCommaPoint,
+
+	"cs": symbolsCommaSpace, "fi": symbolsCommaSpace, "hu": symbolsCommaSpace, "lt": symbolsCommaSpace,
+	"lv": symbolsCommaSpace, "nb": symbolsCommaSpace, "nn": symbolsCommaSpace, "no": symbolsCommaSpace,
+	"ru": symbolsCommaSpace, "sk": symbolsCommaSpace, "sv": symbolsCommaSpace, "uk": symbolsCommaSpace,
+	"be": symbolsCommaSpace,
+
+	"fr":    {Decimal: ",", Group: narrowNbsp, MinGrouping: 1},
+	"es":    {Decimal: ",", Group: ".", MinGrouping: 2},
+	"pl":    {Decimal: ",", Group: nbsp, MinGrouping: 2},
+	"bg":    {Decimal: ",", Group: nbsp, MinGrouping: 2},
+	"pt_pt": {Decimal: ",", Group: nbsp, MinGrouping: 2},
+	"de_ch": {Decimal: ".", Group: "\u2019", MinGrouping: 1},
+	"de_at": {Decimal: ",", Group: nbsp, MinGrouping: 1},
+	"en_za": {Decimal: ",", Group: nbsp, MinGrouping: 1},
+	"et":    {Decimal: ",", Group: nbsp, MinGrouping: 2},
+	"hi":    {Decimal: ".", Group: ",", MinGrouping: 1, SecondaryGrouping: 2},
+	"en_in": {Decimal: ".", Group: ",", MinGrouping: 1, SecondaryGrouping: 2},
+
+	// Latin America, which the countries without their own symbols use, see inLatinAmerica. The US follows
+	// Latin America too.
+	"es_419": symbolsPointComma, "es_ar": symbolsCommaPoint, "es_bo": symbolsCommaPoint, "es_cl": symbolsCommaPoint,
+	"es_co": symbolsCommaPoint, "es_cr": symbolsCommaSpace, "es_ec": symbolsCommaPoint, "es_py": symbolsCommaPoint,
+	"es_uy": symbolsCommaPoint, "es_ve": symbolsCommaPoint, "es_us": symbolsPointComma,
+}
+
+func numberSymbolsFor(locale string) numberSymbols {
+	if symbols, ok := numberSymbolsByLocale[locale]; ok {
+		return symbols
+	}
+	// Latin American locales, e.g. es_mx, use the symbols of Latin America rather than the ones of their language
+	if symbols, ok := numberSymbolsByLocale[localeLanguage(locale)+"_419"]; ok && inLatinAmerica(locale) {
+		return symbols
+	}
+	if symbols, ok := numberSymbolsByLocale[localeLanguage(locale)]; ok {
+		return symbols
+	}
+	return symbolsPointComma
+}
+
+var latinAmerica = language.MustParseRegion("419")
+
+func inLatinAmerica(locale string) bool {
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return false
+	}
+	_, _, region := tag.Raw()
+	return region.IsCountry() && latinAmerica.Contains(region)
+}
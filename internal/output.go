@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DiffOutputDir returns a diff of the changes writing the generated files would make to the output dir, or ""
+// if it's up to date. That includes removing the files of locales that no longer exist.
+func DiffOutputDir(outputDir string, files []GeneratedFile) (string, error) {
+	var sb strings.Builder
+	for _, file := range files {
+		path := filepath.Join(outputDir, file.Name)
+		oldName := path
+		existing, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			oldName = "/dev/null"
+		} else if err != nil {
+			return "", err
+		}
+		if oldName != "/dev/null" && bytes.Equal(existing, file.Content) {
+			continue
+		}
+		diff := UnifiedDiff(oldName, path, existing, file.Content)
+		if diff == "" {
+			diff = fmt.Sprintf("Files %s and %s differ\n", oldName, path)
+		}
+		sb.WriteString(diff)
+	}
+
+	stale, err := StaleFiles(outputDir, files)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range stale {
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(UnifiedDiff(path, "/dev/null", existing, nil))
+	}
+	return sb.String(), nil
+}
+
+// StaleFiles returns the paths of files in the output dir that were generated, but are no longer, like the file
+// of a removed locale. Files without the generated header are hand-written, and never returned.
+func StaleFiles(outputDir string, files []GeneratedFile) ([]string, error) {
+	entries, err := os.ReadDir(outputDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	generated := make(map[string]bool, len(files))
+	for _, file := range files {
+		generated[file.Name] = true
+	}
+
+	stale := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" || generated[entry.Name()] {
+			continue
+		}
+		path := filepath.Join(outputDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(content, []byte(generatedHeader)) {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// RemoveStaleFiles removes the files returned by StaleFiles, and returns their paths.
+func RemoveStaleFiles(outputDir string, files []GeneratedFile) ([]string, error) {
+	stale, err := StaleFiles(outputDir, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return stale, nil
+}
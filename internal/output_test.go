@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	existing := map[string]string{
+		"sv.go":     generatedHeader + "\npackage i18n\n\nvar x = 1\n",
+		"en.go":     generatedHeader + "\npackage i18n\n",
+		"fi.go":     generatedHeader + "\npackage i18n\n",
+		"nl.go":     generatedHeader + "\npackage i18n",
+		"custom.go": "package i18n\n",
+		"notes.txt": generatedHeader + "\n",
+	}
+	for name, content := range existing {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []GeneratedFile{
+		{Name: "sv.go", Content: []byte(generatedHeader + "\npackage i18n\n\nvar x = 2\n")},
+		{Name: "en.go", Content: []byte(generatedHeader + "\npackage i18n\n")},
+		{Name: "de.go", Content: []byte(generatedHeader + "\npackage i18n\n")},
+		{Name: "nl.go", Content: []byte(generatedHeader + "\npackage i18n\n")},
+	}
+
+	stale, err := StaleFiles(dir, files)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stale) != 1 || stale[0] != filepath.Join(dir, "fi.go") {
+		t.Errorf("Expected only fi.go to be stale, but got %v", stale)
+	}
+
+	diff, err := DiffOutputDir(dir, files)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"+++ " + filepath.Join(dir, "sv.go") + "\n@@ -1,4 +1,4 @@\n " + generatedHeader + "\n package i18n\n \n-var x = 1\n+var x = 2\n",
+		"--- /dev/null\n+++ " + filepath.Join(dir, "de.go") + "\n",
+		"--- " + filepath.Join(dir, "fi.go") + "\n+++ /dev/null\n",
+		"+++ " + filepath.Join(dir, "nl.go") + "\n@@ -1,2 +1,2 @@\n " + generatedHeader + "\n-package i18n\n\\ No newline at end of file\n+package i18n\n",
+	} {
+		if !strings.Contains(diff, expected) {
+			t.Errorf("Expected diff to contain %q, but got:\n%s", expected, diff)
+		}
+	}
+	for _, unexpected := range []string{"en.go", "custom.go", "notes.txt"} {
+		if strings.Contains(diff, unexpected) {
+			t.Errorf("Expected no changes to %s, but got:\n%s", unexpected, diff)
+		}
+	}
+
+	if diff, err := DiffOutputDir(filepath.Join(dir, "missing"), nil); err != nil || diff != "" {
+		t.Errorf("Expected no changes to a missing dir, but got %q, %v", diff, err)
+	}
+}
+
+func TestRemoveStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := map[string]string{
+		"sv.go":          generatedHeader + "\npackage i18n\n",
+		"de.go":          generatedHeader + "\npackage i18n\n",
+		"custom.go":      "package i18n\n",
+		"custom_test.go": "// Code generated by something else; DO NOT EDIT.\npackage i18n\n",
+	}
+	for name, content := range existing {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := RemoveStaleFiles(dir, []GeneratedFile{{Name: "sv.go"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != filepath.Join(dir, "de.go") {
+		t.Errorf("Expected only de.go to be removed, but got %v", removed)
+	}
+	for name := range existing {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != (name != "de.go") {
+			t.Errorf("Unexpected existence of %s: %v", name, err)
+		}
+	}
+}
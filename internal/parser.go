@@ -2,12 +2,17 @@ package internal
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 )
 
 type TranslateFuncParam struct {
 	Name string
 	Type string
+	// Explicit is set when the type is given by an annotation (`{price:float}`) or by driving a
+	// plural block. Other parameters are strings, unless they inherit the type from the base locale.
+	Explicit bool
 }
 
 type TranslateFunc struct {
@@ -15,6 +20,75 @@ type TranslateFunc struct {
 	Name      string
 	Params    []TranslateFuncParam
 	Body      string
+	// Fallback is the locale the translation is delegated to, when it's missing in a regional locale
+	Fallback string
+	// Missing is set when the translation is missing, but allowed to fall back to the base locale
+	Missing bool
+
+	conventions localeConventions
+	segments    []segment
+}
+
+type segmentType int
+
+const (
+	segmentText segmentType = iota
+	segmentSub
+	segmentPlural
+	segmentSelect
+)
+
+// segment is a parsed token of a template, kept around to be able to render the body again once
+// parameter types have been resolved against the base locale.
+type segment struct {
+	Type   segmentType
+	Text   string      // Text segments only
+	Param  string      // Substituted parameter, or the parameter driving a plural block
+	Block  pluralBlock // Plural segments only
+	Select selectBlock // Select segments only
+
+	// Substitutions only
+	Annotated bool   // Whether the substitution has any annotations
+	Style     string // Formatting style, e.g. "raw" or "date"
+	Width     string // Width of date and time styles, e.g. "short"
+}
+
+// annotation is what a substitution like `{when:date:long}` is annotated with.
+type annotation struct {
+	Type  string // Go type of the parameter
+	Style string // How the parameter is formatted
+	Width string // Width of date and time styles
+}
+
+// Keywords that substitutions can be annotated with, separated by ':'.
+var annotationKeywords = map[string]annotation{
+	"string":    {Type: "string"},
+	"int":       {Type: "int"},
+	"float":     {Type: "float64"},
+	"Stringer":  {Type: "fmt.Stringer"},
+	"any":       {Type: "any"},
+	"raw":       {Style: "raw"}, // Numbers without locale specific separators
+	"date":      {Type: "time.Time", Style: "date"},
+	"time":      {Type: "time.Time", Style: "time"},
+	"datetime":  {Type: "time.Time", Style: "datetime"},
+	WidthShort:  {Width: WidthShort},
+	WidthMedium: {Width: WidthMedium},
+	WidthLong:   {Width: WidthLong},
+	WidthFull:   {Width: WidthFull},
+}
+
+var typeVerbs = map[string]string{
+	"string":       "%s",
+	"int":          "%d",
+	"float64":      "%v",
+	"time.Time":    "%v",
+	"fmt.Stringer": "%s",
+	"any":          "%v",
+}
+
+var typeImports = map[string]string{
+	"time.Time":    "time",
+	"fmt.Stringer": "fmt",
 }
 
 func (t *TranslateFunc) Signature() string {
@@ -29,6 +103,95 @@ func (t *TranslateFunc) ParamsList() string {
 	return strings.Join(params, ", ")
 }
 
+// SignatureImports returns the packages needed by the parameter types, sorted.
+func (t *TranslateFunc) SignatureImports() []string {
+	imports := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, param := range t.Params {
+		if pkg, ok := typeImports[param.Type]; ok && !seen[pkg] {
+			imports = append(imports, pkg)
+			seen[pkg] = true
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// usesDates returns whether the body formats dates or times with the names of the locale.
+func (t *TranslateFunc) usesDates() bool {
+	if t.Fallback != "" {
+		return false // Formatted by the fallback
+	}
+	for _, param := range t.Params {
+		if param.Type == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TranslateFunc) paramType(name string) string {
+	for _, param := range t.Params {
+		if param.Name == name {
+			return param.Type
+		}
+	}
+	return ""
+}
+
+// baseAnnotation returns the style and width of the first annotated substitution of param.
+func (t *TranslateFunc) baseAnnotation(param string) (string, string) {
+	for _, seg := range t.segments {
+		if seg.Type == segmentSub && seg.Annotated && seg.Param == param {
+			return seg.Style, seg.Width
+		}
+	}
+	return "", ""
+}
+
+// inheritAnnotations gives parameters without an explicit type the type they have in the base
+// locale, and substitutions without annotations the formatting style they have in the base locale.
+func (t *TranslateFunc) inheritAnnotations(base TranslateFunc) {
+	changed := false
+	for i, param := range t.Params {
+		baseType := base.paramType(param.Name)
+		if param.Explicit || baseType == "" || baseType == param.Type {
+			continue
+		}
+		t.Params[i].Type = baseType
+		changed = true
+	}
+	for i, seg := range t.segments {
+		if seg.Type != segmentSub || seg.Annotated {
+			continue
+		}
+		if style, width := base.baseAnnotation(seg.Param); style != "" || width != "" {
+			t.segments[i].Style = style
+			t.segments[i].Width = width
+			changed = true
+		}
+	}
+	if changed {
+		t.Body = t.renderBody()
+	}
+}
+
+// delegateTo returns a translation with the same signature, that calls the translation of another locale.
+func (t *TranslateFunc) delegateTo(locale string, sectionPath []string) TranslateFunc {
+	paramNames := make([]string, len(t.Params))
+	for i, param := range t.Params {
+		paramNames[i] = param.Name
+	}
+	return TranslateFunc{
+		DocString:   t.DocString,
+		Name:        t.Name,
+		Params:      append([]TranslateFuncParam{}, t.Params...),
+		Body:        fmt.Sprintf("\treturn (&%s{}).%s(%s)\n", localeStructName(locale, sectionPath), t.Name, strings.Join(paramNames, ", ")),
+		Fallback:    locale,
+		conventions: t.conventions,
+	}
+}
+
 func createDocString(value string) string {
 	lines := strings.Split(value, "\n")
 	var docLines []string
@@ -38,94 +201,255 @@ func createDocString(value string) string {
 	return strings.Join(docLines, "\n")
 }
 
-func parseTranslateFunc(tomlKey string, value string) (TranslateFunc, error) {
-	tokens := tokenize(value)
+// docText returns the template as shown in docstrings, with escaped characters in text unescaped.
+func docText(value string, tokens []Token) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		if token.Type == TokenText {
+			sb.WriteString(token.Value)
+		} else {
+			sb.WriteString(value[token.Start:token.End])
+		}
+	}
+	return sb.String()
+}
 
-	trParams := make([]TranslateFuncParam, 0)
-	seenFuncArgs := make(map[string]bool)
-	fmtArgs := make([]string, 0)
+// splitPluralDriver splits the parameter driving a plural block (`{{folders: folder|folders}}`) from
+// its forms. Blocks without an explicit driver are driven by `count`.
+func splitPluralDriver(value string) (string, string) {
+	match := pluralDriverRegexp.FindStringSubmatch(value)
+	if match == nil || isPluralCategory(match[1]) {
+		return "count", value
+	}
+	return match[1], value[len(match[0]):]
+}
+
+var pluralDriverRegexp = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*:\s*`)
+
+func isPluralCategory(name string) bool {
+	switch name {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return true
+	}
+	return false
+}
+
+// splitAnnotation splits a substitution like `when:date:long` into its name and annotation.
+func splitAnnotation(value string) (string, annotation, error) {
+	parts := strings.Split(value, ":")
+	name := strings.TrimSpace(parts[0])
+	result := annotation{}
+	merge := func(field *string, value string, keyword string) error {
+		if value == "" {
+			return nil
+		}
+		if *field != "" && *field != value {
+			return fmt.Errorf("conflicting annotation '%s' for '%s'", keyword, name)
+		}
+		*field = value
+		return nil
+	}
+
+	for _, keyword := range parts[1:] {
+		keyword = strings.TrimSpace(keyword)
+		keywordAnnotation, ok := annotationKeywords[keyword]
+		if !ok {
+			known := make([]string, 0, len(annotationKeywords))
+			for keyword := range annotationKeywords {
+				known = append(known, keyword)
+			}
+			sort.Strings(known)
+			return "", annotation{}, fmt.Errorf("unexpected annotation '%s' for '%s' (expected one of %s)", keyword, name, strings.Join(known, ", "))
+		}
+		for _, err := range []error{
+			merge(&result.Type, keywordAnnotation.Type, keyword),
+			merge(&result.Style, keywordAnnotation.Style, keyword),
+			merge(&result.Width, keywordAnnotation.Width, keyword),
+		} {
+			if err != nil {
+				return "", annotation{}, err
+			}
+		}
+	}
+
+	if result.Width != "" && result.Type != "time.Time" {
+		return "", annotation{}, fmt.Errorf("'%s' only applies to date and time substitutions, in '%s'", result.Width, name)
+	}
+	return name, result, nil
+}
+
+// templateError is an error in a translation value, at a byte offset into the (decoded) value.
+type templateError struct {
+	Key    string
+	Value  string
+	Offset int
+	Err    error
+}
+
+func (e *templateError) Error() string {
+	return fmt.Sprintf("%s, in `%s = \"%s\"`", e.Err, e.Key, e.Value)
+}
 
-	var returnSingular strings.Builder
-	var returnPlural strings.Builder
+func (e *templateError) Unwrap() error {
+	return e.Err
+}
 
-	hasPlural := false
+func parseTranslateFunc(tomlKey string, value string, conventions localeConventions) (TranslateFunc, error) {
+	tokens := tokenize(value)
+	wrapError := func(err error, offset int) error {
+		return &templateError{Key: tomlKey, Value: value, Offset: offset, Err: err}
+	}
 
+	// Resolve explicit types first, since a parameter can be used before it's annotated
+	explicitTypes := make(map[string]string)
+	explicitOffsets := make(map[string]int)
+	setExplicitType := func(name string, goType string, offset int) error {
+		if existing, exists := explicitTypes[name]; exists && existing != goType {
+			return fmt.Errorf("'%s' is used both as %s and %s", name, existing, goType)
+		}
+		if _, exists := explicitTypes[name]; !exists {
+			explicitOffsets[name] = offset
+		}
+		explicitTypes[name] = goType
+		return nil
+	}
 	for _, token := range tokens {
 		if token.Error != "" {
-			return TranslateFunc{}, fmt.Errorf("syntax error: %s, in `%s = \"%s\"`", token.Error, tomlKey, value)
+			return TranslateFunc{}, wrapError(fmt.Errorf("syntax error: %s", token.Error), token.Start)
 		}
+		var err error
 		switch token.Type {
-		case TokenText:
-			escapedValue := strings.ReplaceAll(token.Value, `%`, `%%`)
-			returnSingular.WriteString(escapedValue)
-			returnPlural.WriteString(escapedValue)
 		case TokenSub:
-			if !seenFuncArgs[token.Value] {
-				if token.Value == "count" {
-					// Prepend count
-					trParams = append([]TranslateFuncParam{{
-						Name: "count",
-						Type: "int",
-					}}, trParams...)
-				} else {
-					trParams = append(trParams, TranslateFuncParam{
-						Name: token.Value,
-						Type: "string",
-					})
-				}
-				seenFuncArgs[token.Value] = true
-			}
-			fmtArgs = append(fmtArgs, token.Value)
-			placeholder := "%s"
-			if token.Value == "count" {
-				placeholder = "%d"
+			var name string
+			var subAnnotation annotation
+			if name, subAnnotation, err = splitAnnotation(token.Value); err == nil && subAnnotation.Type != "" {
+				err = setExplicitType(name, subAnnotation.Type, token.Start)
 			}
-			returnSingular.WriteString(placeholder)
-			returnPlural.WriteString(placeholder)
 		case TokenPlural:
-			if !seenFuncArgs["count"] {
-				// Prepend count
-				trParams = append([]TranslateFuncParam{{
-					Name: "count",
-					Type: "int",
-				}}, trParams...)
-				seenFuncArgs["count"] = true
+			driver, _ := splitPluralDriver(token.Value)
+			err = setExplicitType(driver, "int", token.Start)
+		case TokenSelect:
+			var block selectBlock
+			if block, err = parseSelect(token.Value); err == nil {
+				err = setExplicitType(block.Param, "string", token.Start)
 			}
-			hasPlural = true
-			// Split on |, singular first and plural second
-			parts := strings.Split(token.Value, "|")
-			singularForm := parts[0]
-			var pluralForm string
-			if len(parts) == 1 {
-				singularForm = ""
-				pluralForm = parts[0]
-			} else {
-				pluralForm = strings.Join(parts[1:], "")
+		}
+		if err != nil {
+			return TranslateFunc{}, wrapError(err, token.Start)
+		}
+	}
+	if countType, exists := explicitTypes["count"]; exists && countType != "int" {
+		return TranslateFunc{}, wrapError(fmt.Errorf("'count' must be int, but was %s", countType), explicitOffsets["count"])
+	}
+
+	trParams := make([]TranslateFuncParam, 0)
+	seenFuncArgs := make(map[string]bool)
+	addParam := func(name string) {
+		if seenFuncArgs[name] {
+			return
+		}
+		seenFuncArgs[name] = true
+		if name == "count" {
+			// Prepend count
+			trParams = append([]TranslateFuncParam{{
+				Name:     "count",
+				Type:     "int",
+				Explicit: true,
+			}}, trParams...)
+			return
+		}
+		param := TranslateFuncParam{Name: name, Type: "string"}
+		if goType, exists := explicitTypes[name]; exists {
+			param.Type = goType
+			param.Explicit = true
+		}
+		trParams = append(trParams, param)
+	}
+
+	segments := make([]segment, 0, len(tokens))
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenText:
+			segments = append(segments, segment{Type: segmentText, Text: token.Value})
+		case TokenSub:
+			name, subAnnotation, _ := splitAnnotation(token.Value)
+			addParam(name)
+			segments = append(segments, segment{
+				Type:      segmentSub,
+				Param:     name,
+				Annotated: subAnnotation != annotation{},
+				Style:     subAnnotation.Style,
+				Width:     subAnnotation.Width,
+			})
+		case TokenPlural:
+			driver, forms := splitPluralDriver(token.Value)
+			addParam(driver)
+			block, err := conventions.Plural.parseBlock(forms)
+			if err != nil {
+				return TranslateFunc{}, wrapError(err, token.Start)
 			}
-			returnSingular.WriteString(singularForm)
-			returnPlural.WriteString(pluralForm)
+			segments = append(segments, segment{Type: segmentPlural, Param: driver, Block: block})
+		case TokenSelect:
+			block, _ := parseSelect(token.Value)
+			addParam(block.Param)
+			segments = append(segments, segment{Type: segmentSelect, Param: block.Param, Select: block})
 		}
 	}
 
+	trFunc := TranslateFunc{
+		Name:        toPublicName(tomlKey),
+		DocString:   createDocString(docText(value, tokens)), // Create properly formatted multiline comment
+		Params:      trParams,
+		conventions: conventions,
+		segments:    segments,
+	}
+	trFunc.Body = trFunc.renderBody()
+	return trFunc, nil
+}
+
+func (t *TranslateFunc) renderBody() string {
 	var body strings.Builder
+	var format strings.Builder
+	fmtArgs := make([]string, 0)
 
-	if hasPlural {
-		body.WriteString("\tif count == 1 {\n")
-		genSprintfReturn(&body, returnSingular.String(), fmtArgs)
-		body.WriteString("\t} else {\n")
-		genSprintfReturn(&body, returnPlural.String(), fmtArgs)
-		body.WriteString("\t}\n")
-	} else {
-		genSprintfReturn(&body, returnSingular.String(), fmtArgs)
+	pluralIndex := 0
+	selectIndex := 0
+	for _, seg := range t.segments {
+		switch seg.Type {
+		case segmentText:
+			format.WriteString(strings.ReplaceAll(seg.Text, `%`, `%%`))
+		case segmentSub:
+			paramType := t.paramType(seg.Param)
+			if numberArg := genNumberArg(seg.Param, paramType, t.conventions.Numbers); numberArg != "" && seg.Style != "raw" {
+				format.WriteString("%s")
+				fmtArgs = append(fmtArgs, numberArg)
+			} else if paramType == "float64" {
+				// Raw floats aren't grouped, but %v would write large ones with an exponent, e.g. 1e+06
+				format.WriteString("%s")
+				fmtArgs = append(fmtArgs, genNumberArg(seg.Param, paramType, numberSymbols{Decimal: ".", MinGrouping: 1}))
+			} else if paramType == "time.Time" {
+				format.WriteString("%s")
+				fmtArgs = append(fmtArgs, genTimeArg(seg.Param, seg.Style, seg.Width, t.conventions))
+			} else {
+				format.WriteString(typeVerbs[paramType])
+				fmtArgs = append(fmtArgs, seg.Param)
+			}
+		case segmentPlural:
+			// The selected form is passed as an argument, so it's not subject to format escaping
+			varName := fmt.Sprintf("plural%d", pluralIndex)
+			pluralIndex++
+			genPluralSwitch(&body, varName, seg.Param, t.conventions, seg.Block)
+			format.WriteString("%s")
+			fmtArgs = append(fmtArgs, varName)
+		case segmentSelect:
+			varName := fmt.Sprintf("select%d", selectIndex)
+			selectIndex++
+			genSelectSwitch(&body, varName, seg.Select)
+			format.WriteString("%s")
+			fmtArgs = append(fmtArgs, varName)
+		}
 	}
 
-	// Create properly formatted multiline comment
-	docString := createDocString(value)
-	
-	return TranslateFunc{
-		Name:      toPublicName(tomlKey),
-		DocString: docString,
-		Params:    trParams,
-		Body:      body.String(),
-	}, nil
+	genSprintfReturn(&body, format.String(), fmtArgs)
+	return body.String()
 }
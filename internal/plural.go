@@ -0,0 +1,349 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Plural categories as defined by CLDR, in their canonical order.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+type pluralCategory struct {
+	Name string
+	// Go condition selecting the category, written in terms of the operand `n`.
+	// Empty for the last category, which catches everything else.
+	Cond string
+	// Optional categories can be left out of positional forms, and then use the 'other' form
+	Optional bool
+}
+
+// pluralRule lists the categories a locale distinguishes between for integer counts, in CLDR order.
+// Counts are always ints, so categories that CLDR only uses for fractions (e.g. 'other' in Polish and
+// Russian) are left out, since they would never be rendered.
+type pluralRule []pluralCategory
+
+// required returns the categories that can't be left out of positional forms.
+func (r pluralRule) required() pluralRule {
+	required := make(pluralRule, 0, len(r))
+	for _, category := range r {
+		if !category.Optional {
+			required = append(required, category)
+		}
+	}
+	return required
+}
+
+func (r pluralRule) Names() []string {
+	names := make([]string, len(r))
+	for i, category := range r {
+		names[i] = category.Name
+	}
+	return names
+}
+
+var (
+	ruleOther = pluralRule{
+		{Name: PluralOther},
+	}
+	ruleOneOther = pluralRule{
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralOther},
+	}
+	ruleZeroOneOther = pluralRule{
+		{Name: PluralOne, Cond: "n == 0 || n == 1"},
+		{Name: PluralOther},
+	}
+	// Romance languages use 'many' for millions, e.g. "1 million de fichiers"
+	ruleOneManyOther = pluralRule{
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralMany, Cond: "n != 0 && n%1000000 == 0", Optional: true},
+		{Name: PluralOther},
+	}
+	ruleZeroOneManyOther = pluralRule{
+		{Name: PluralOne, Cond: "n == 0 || n == 1"},
+		{Name: PluralMany, Cond: "n != 0 && n%1000000 == 0", Optional: true},
+		{Name: PluralOther},
+	}
+	ruleEastSlavic = pluralRule{
+		{Name: PluralOne, Cond: "n%10 == 1 && n%100 != 11"},
+		{Name: PluralFew, Cond: "n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14)"},
+		{Name: PluralMany},
+	}
+	ruleWestSlavic = pluralRule{
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralFew, Cond: "n >= 2 && n <= 4"},
+		{Name: PluralOther},
+	}
+	ruleSouthSlavic = pluralRule{
+		{Name: PluralOne, Cond: "n%10 == 1 && n%100 != 11"},
+		{Name: PluralFew, Cond: "n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14)"},
+		{Name: PluralOther},
+	}
+	ruleOneMod10 = pluralRule{
+		{Name: PluralOne, Cond: "n%10 == 1 && n%100 != 11"},
+		{Name: PluralOther},
+	}
+)
+
+// Plural rules keyed by locale or language, derived from the CLDR cardinal plural rules.
+var pluralRules = map[string]pluralRule{
+	// Only 'other'
+	"id": ruleOther, "ja": ruleOther, "km": ruleOther, "ko": ruleOther, "lo": ruleOther, "ms": ruleOther,
+	"my": ruleOther, "th": ruleOther, "vi": ruleOther, "yue": ruleOther, "zh": ruleOther,
+
+	// 'one' for exactly 1
+	"af": ruleOneOther, "az": ruleOneOther, "bg": ruleOneOther, "ca": ruleOneManyOther, "da": ruleOneOther,
+	"de": ruleOneOther, "el": ruleOneOther, "en": ruleOneOther, "es": ruleOneManyOther, "et": ruleOneOther,
+	"eu": ruleOneOther, "fi": ruleOneOther, "gl": ruleOneOther, "hu": ruleOneOther, "it": ruleOneManyOther,
+	"ka": ruleOneOther, "kk": ruleOneOther, "ky": ruleOneOther, "mn": ruleOneOther, "mr": ruleOneOther,
+	"nb": ruleOneOther, "ne": ruleOneOther, "nl": ruleOneOther, "nn": ruleOneOther, "no": ruleOneOther,
+	"pt_pt": ruleOneManyOther, "sq": ruleOneOther, "sv": ruleOneOther, "sw": ruleOneOther, "ta": ruleOneOther,
+	"te": ruleOneOther, "tr": ruleOneOther, "ur": ruleOneOther, "uz": ruleOneOther,
+
+	// 'one' for 0 and 1
+	"am": ruleZeroOneOther, "bn": ruleZeroOneOther, "fa": ruleZeroOneOther, "fr": ruleZeroOneManyOther,
+	"gu": ruleZeroOneOther, "hi": ruleZeroOneOther, "hy": ruleZeroOneOther, "kn": ruleZeroOneOther,
+	"pt": ruleZeroOneManyOther, "zu": ruleZeroOneOther,
+
+	"be": ruleEastSlavic, "ru": ruleEastSlavic, "uk": ruleEastSlavic,
+	"cs": ruleWestSlavic, "sk": ruleWestSlavic,
+	"bs": ruleSouthSlavic, "hr": ruleSouthSlavic, "sr": ruleSouthSlavic,
+	"is": ruleOneMod10, "mk": ruleOneMod10,
+
+	"pl": {
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralFew, Cond: "n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14)"},
+		{Name: PluralMany},
+	},
+	"lt": {
+		{Name: PluralOne, Cond: "n%10 == 1 && (n%100 < 11 || n%100 > 19)"},
+		{Name: PluralFew, Cond: "n%10 >= 2 && (n%100 < 11 || n%100 > 19)"},
+		{Name: PluralOther},
+	},
+	"lv": {
+		{Name: PluralZero, Cond: "n%10 == 0 || (n%100 >= 11 && n%100 <= 19)"},
+		{Name: PluralOne, Cond: "n%10 == 1 && n%100 != 11"},
+		{Name: PluralOther},
+	},
+	"ro": {
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralFew, Cond: "n == 0 || (n%100 >= 1 && n%100 <= 19)"},
+		{Name: PluralOther},
+	},
+	"sl": {
+		{Name: PluralOne, Cond: "n%100 == 1"},
+		{Name: PluralTwo, Cond: "n%100 == 2"},
+		{Name: PluralFew, Cond: "n%100 == 3 || n%100 == 4"},
+		{Name: PluralOther},
+	},
+	"he": {
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralTwo, Cond: "n == 2"},
+		{Name: PluralOther},
+	},
+	"ar": {
+		{Name: PluralZero, Cond: "n == 0"},
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralTwo, Cond: "n == 2"},
+		{Name: PluralFew, Cond: "n%100 >= 3 && n%100 <= 10"},
+		{Name: PluralMany, Cond: "n%100 >= 11"},
+		{Name: PluralOther},
+	},
+	"cy": {
+		{Name: PluralZero, Cond: "n == 0"},
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralTwo, Cond: "n == 2"},
+		{Name: PluralFew, Cond: "n == 3"},
+		{Name: PluralMany, Cond: "n == 6"},
+		{Name: PluralOther},
+	},
+	"ga": {
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralTwo, Cond: "n == 2"},
+		{Name: PluralFew, Cond: "n >= 3 && n <= 6"},
+		{Name: PluralMany, Cond: "n >= 7 && n <= 10"},
+		{Name: PluralOther},
+	},
+	"gd": {
+		{Name: PluralOne, Cond: "n == 1 || n == 11"},
+		{Name: PluralTwo, Cond: "n == 2 || n == 12"},
+		{Name: PluralFew, Cond: "(n >= 3 && n <= 10) || (n >= 13 && n <= 19)"},
+		{Name: PluralOther},
+	},
+	"mt": {
+		{Name: PluralOne, Cond: "n == 1"},
+		{Name: PluralTwo, Cond: "n == 2"},
+		{Name: PluralFew, Cond: "n == 0 || (n%100 >= 3 && n%100 <= 10)"},
+		{Name: PluralMany, Cond: "n%100 >= 11 && n%100 <= 19"},
+		{Name: PluralOther},
+	},
+	"fil": {
+		{Name: PluralOne, Cond: "n%10 != 4 && n%10 != 6 && n%10 != 9"},
+		{Name: PluralOther},
+	},
+}
+
+// pluralRuleFor returns the plural rule for a locale, trying the full locale before its language.
+// Unknown languages get the 'one'/'other' rule, which is what most languages use.
+func pluralRuleFor(locale string) pluralRule {
+	if rule, ok := pluralRules[locale]; ok {
+		return rule
+	}
+	if rule, ok := pluralRules[localeLanguage(locale)]; ok {
+		return rule
+	}
+	return ruleOneOther
+}
+
+var operandRegexp = regexp.MustCompile(`\bn\b`)
+
+// condition returns the Go condition for the category at index, applied to operand.
+func (r pluralRule) condition(index int, operand string) string {
+	return operandRegexp.ReplaceAllString(r[index].Cond, operand)
+}
+
+type pluralExact struct {
+	Value int
+	Form  string
+}
+
+// pluralBlock is the content of a `{{...}}` block, resolved against the plural rule of the locale.
+type pluralBlock struct {
+	Exact []pluralExact // Exact matches (`=0: none`), checked before the categories
+	Forms []string      // One form per category in the rule
+	Named bool          // Whether the forms are named, in which '#' is the count
+}
+
+var namedFormRegexp = regexp.MustCompile(`^\s*(zero|one|two|few|many|other|=\d+)\s*:`)
+
+// parseBlock parses the content of a plural block, either as positional forms (`apple|apples`) or
+// as named categories (`=0: no apples, one: apple, other: apples`).
+func (r pluralRule) parseBlock(value string) (pluralBlock, error) {
+	if namedFormRegexp.MatchString(value) {
+		return r.parseNamedForms(value)
+	}
+
+	parts := strings.Split(value, "|")
+	required := r.required()
+	if len(parts) == 1 && len(required) == 2 {
+		// Shorthand, e.g. `apple{{s}}`, only specifies the suffix of the last form
+		parts = []string{"", parts[0]}
+	}
+	if len(parts) == len(r) {
+		return pluralBlock{Forms: parts}, nil
+	}
+	if len(parts) != len(required) {
+		expected := fmt.Sprintf("%d plural forms (%s)", len(r), strings.Join(r.Names(), "|"))
+		if len(required) != len(r) {
+			expected = fmt.Sprintf("%d plural forms (%s) or %d (%s)", len(required), strings.Join(required.Names(), "|"), len(r), strings.Join(r.Names(), "|"))
+		}
+		return pluralBlock{}, fmt.Errorf("expected %s but found %d in {{%s}}", expected, len(parts), value)
+	}
+
+	// Optional categories use the 'other' form, which is the last one
+	block := pluralBlock{}
+	next := 0
+	for _, category := range r {
+		if category.Optional {
+			block.Forms = append(block.Forms, parts[len(parts)-1])
+			continue
+		}
+		block.Forms = append(block.Forms, parts[next])
+		next++
+	}
+	return block, nil
+}
+
+func (r pluralRule) parseNamedForms(value string) (pluralBlock, error) {
+	// Separate by '|' if used, so that forms can contain commas
+	separator := ","
+	if strings.Contains(value, "|") {
+		separator = "|"
+	}
+
+	block := pluralBlock{Named: true}
+	formsByCategory := make(map[string]string)
+	seenExact := make(map[int]bool)
+	for _, entry := range strings.Split(value, separator) {
+		match := namedFormRegexp.FindStringSubmatch(entry)
+		if match == nil {
+			return pluralBlock{}, fmt.Errorf("expected a plural category (%s) or exact match (=0) before '%s' in {{%s}}", strings.Join(r.Names(), ", "), strings.TrimSpace(entry), value)
+		}
+		name := match[1]
+		form := strings.TrimSpace(entry[len(match[0]):])
+		if strings.ContainsAny(form, "{}") {
+			return pluralBlock{}, fmt.Errorf("plural form '%s' can only contain text, use '#' for the count in {{%s}}", name, value)
+		}
+
+		if strings.HasPrefix(name, "=") {
+			exactValue, err := strconv.Atoi(name[1:])
+			if err != nil {
+				return pluralBlock{}, fmt.Errorf("invalid exact match '%s' in {{%s}}", name, value)
+			}
+			if seenExact[exactValue] {
+				return pluralBlock{}, fmt.Errorf("duplicate exact match '%s' in {{%s}}", name, value)
+			}
+			seenExact[exactValue] = true
+			block.Exact = append(block.Exact, pluralExact{Value: exactValue, Form: form})
+			continue
+		}
+
+		if _, exists := formsByCategory[name]; exists {
+			return pluralBlock{}, fmt.Errorf("duplicate plural category '%s' in {{%s}}", name, value)
+		}
+		if name != PluralOther && !r.has(name) {
+			return pluralBlock{}, fmt.Errorf("'%s' is not a plural category of this locale (expected %s) in {{%s}}", name, strings.Join(r.Names(), ", "), value)
+		}
+		formsByCategory[name] = form
+	}
+
+	// Categories that are left out use 'other', if given
+	otherForm, hasOther := formsByCategory[PluralOther]
+	for _, category := range r {
+		form, exists := formsByCategory[category.Name]
+		if !exists {
+			if !hasOther {
+				return pluralBlock{}, fmt.Errorf("missing plural category '%s' in {{%s}}", category.Name, value)
+			}
+			form = otherForm
+		}
+		block.Forms = append(block.Forms, form)
+	}
+	return block, nil
+}
+
+func (r pluralRule) has(name string) bool {
+	for _, category := range r {
+		if category.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formExpr returns the Go expression of a form. In named forms, '#' is replaced by the count, like in ICU
+// messages, e.g. `other: # apples`.
+func (b pluralBlock) formExpr(form string, countArg string) string {
+	if !b.Named || !strings.Contains(form, "#") {
+		return strconv.Quote(form)
+	}
+	exprs := make([]string, 0)
+	for i, part := range strings.Split(form, "#") {
+		if i > 0 {
+			exprs = append(exprs, countArg)
+		}
+		if part != "" {
+			exprs = append(exprs, strconv.Quote(part))
+		}
+	}
+	return strings.Join(exprs, " + ")
+}
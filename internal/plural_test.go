@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPluralRuleFor(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected []string
+	}{
+		{locale: "en", expected: []string{"one", "other"}},
+		{locale: "en_uk", expected: []string{"one", "other"}},
+		{locale: "ja", expected: []string{"other"}},
+		{locale: "pl", expected: []string{"one", "few", "many"}},
+		{locale: "ar", expected: []string{"zero", "one", "two", "few", "many", "other"}},
+		{locale: "pt", expected: []string{"one", "many", "other"}},
+		{locale: "fr", expected: []string{"one", "many", "other"}},
+		{locale: "es_mx", expected: []string{"one", "many", "other"}},
+		{locale: "xx", expected: []string{"one", "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			names := pluralRuleFor(tt.locale).Names()
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected categories %v, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	// Portugal uses a different rule than Brazil for 0
+	if cond := pluralRuleFor("pt").condition(0, "count"); cond != "count == 0 || count == 1" {
+		t.Errorf("Unexpected condition for pt: %s", cond)
+	}
+	if cond := pluralRuleFor("pt_pt").condition(0, "count"); cond != "count == 1" {
+		t.Errorf("Unexpected condition for pt_pt: %s", cond)
+	}
+	if cond := pluralRuleFor("fr").condition(1, "count"); cond != "count != 0 && count%1000000 == 0" {
+		t.Errorf("Unexpected condition for many in fr: %s", cond)
+	}
+}
+
+func TestPluralRule_Forms(t *testing.T) {
+	tests := []struct {
+		name          string
+		locale        string
+		value         string
+		expected      []string
+		errorContains string
+	}{
+		{name: "shorthand", locale: "en", value: "s", expected: []string{"", "s"}},
+		{name: "one and other", locale: "en", value: "criterion|criteria", expected: []string{"criterion", "criteria"}},
+		{name: "single category", locale: "ja", value: "つ", expected: []string{"つ"}},
+		{name: "three forms", locale: "pl", value: "plik|pliki|plików", expected: []string{"plik", "pliki", "plików"}},
+		{name: "too many forms", locale: "en", value: "a|b|c", errorContains: "expected 2 plural forms (one|other) but found 3"},
+		{name: "too few forms", locale: "pl", value: "plik|pliki", errorContains: "expected 3 plural forms (one|few|many) but found 2"},
+		{name: "no shorthand for three forms", locale: "ru", value: "ов", errorContains: "expected 3 plural forms"},
+		{name: "optional many", locale: "fr", value: "fichier|fichiers", expected: []string{"fichier", "fichiers", "fichiers"}},
+		{name: "optional many given", locale: "fr", value: "fichier|de fichiers|fichiers", expected: []string{"fichier", "de fichiers", "fichiers"}},
+		{name: "optional many shorthand", locale: "es", value: "s", expected: []string{"", "s", "s"}},
+		{name: "optional many too many forms", locale: "it", value: "a|b|c|d", errorContains: "expected 2 plural forms (one|other) or 3 (one|many|other) but found 4"},
+		{name: "named optional many", locale: "fr", value: "one: fichier, other: fichiers", expected: []string{"fichier", "fichiers", "fichiers"}},
+		{name: "named", locale: "pl", value: "one: plik, few: pliki, many: plików", expected: []string{"plik", "pliki", "plików"}},
+		{name: "named out of order", locale: "en", value: "other: apples, one: apple", expected: []string{"apple", "apples"}},
+		{name: "named with pipes", locale: "en", value: "one: a, b | other: c, d", expected: []string{"a, b", "c, d"}},
+		{name: "named falls back to other", locale: "pl", value: "one: plik, few: pliki, other: plików", expected: []string{"plik", "pliki", "plików"}},
+		{name: "named missing category", locale: "pl", value: "one: plik, few: pliki", errorContains: "missing plural category 'many'"},
+		{name: "named unknown category", locale: "en", value: "one: apple, few: apples, other: apples", errorContains: "'few' is not a plural category"},
+		{name: "named duplicate category", locale: "en", value: "one: a, one: b, other: c", errorContains: "duplicate plural category 'one'"},
+		{name: "named without category", locale: "en", value: "one: apple, apples", errorContains: "expected a plural category"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := pluralRuleFor(tt.locale).parseBlock(tt.value)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing '%s', got: %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(block.Forms, tt.expected) {
+				t.Errorf("Expected forms %q, got %q", tt.expected, block.Forms)
+			}
+		})
+	}
+}
+
+func TestPluralRule_ExactMatches(t *testing.T) {
+	block, err := pluralRuleFor("en").parseBlock("=0: no apples, one: an apple, =12: a dozen apples, other: apples")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []pluralExact{{Value: 0, Form: "no apples"}, {Value: 12, Form: "a dozen apples"}}
+	if !reflect.DeepEqual(block.Exact, expected) {
+		t.Errorf("Expected exact matches %v, got %v", expected, block.Exact)
+	}
+
+	if _, err := pluralRuleFor("en").parseBlock("=0: none, =0: nothing, other: some"); err == nil || !strings.Contains(err.Error(), "duplicate exact match '=0'") {
+		t.Errorf("Expected duplicate exact match error, got: %v", err)
+	}
+}
+
+func TestParseContent_PluralForms(t *testing.T) {
+	result := parseContent("pl.toml", "pl", "files = \"{count} {{plik|pliki|plików}}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	body := result.root["files"].Body
+	for _, expected := range []string{
+		"case count == 1:",
+		"case count%10 >= 2 && count%10 <= 4 && (count%100 < 12 || count%100 > 14):",
+		`plural0 = "plików"`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected body to contain %q, got:\n%s", expected, body)
+		}
+	}
+
+	result = parseContent("en.toml", "en", "apples = \"{{=0: no apples, one: one apple, other: apples}}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	body = result.root["apples"].Body
+	if !strings.Contains(body, "case count == 0:\n\t\tplural0 = \"no apples\"\n\tcase count == 1:") {
+		t.Errorf("Expected exact match before categories, got:\n%s", body)
+	}
+
+	result = parseContent("pl.toml", "pl", "files = \"{count} plik{{ów}}\"")
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "expected 3 plural forms") {
+		t.Errorf("Expected plural form count error, got: %v", result.Errors)
+	}
+}
+
+func TestParseContent_PluralCount(t *testing.T) {
+	result := parseContent("de.toml", "de", "apples = \"{{=0: keine Äpfel, one: ein Apfel, other: # Äpfel (#)}}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	body := result.root["apples"].Body
+	for _, expected := range []string{
+		`plural0 = "keine Äpfel"`,
+		`plural0 = formatInt(count, ".", 1, 3) + " Äpfel (" + formatInt(count, ".", 1, 3) + ")"`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected body to contain %q, got:\n%s", expected, body)
+		}
+	}
+
+	// '#' is only the count in named forms
+	result = parseContent("en.toml", "en", "rank = \"{{#1|#1s}}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if body := result.root["rank"].Body; !strings.Contains(body, `plural0 = "#1s"`) {
+		t.Errorf("Expected positional form to be kept as is, got:\n%s", body)
+	}
+
+	result = parseContent("en.toml", "en", "apples = \"{{one: an apple, other: {count} apples}}\"")
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "en.toml:1:") || !strings.Contains(result.Errors[0].Error(), "plural form 'other' can only contain text, use '#' for the count") {
+		t.Errorf("Expected located plural form error, got: %v", result.Errors)
+	}
+}
+
+func TestParseContent_PluralDrivers(t *testing.T) {
+	result := parseContent("en.toml", "en", "summary = \"{files} file{{files: s}} in {folders} {{folders: folder|folders}}, {count} item{{s}}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	trFunc := result.root["summary"]
+	if sig := trFunc.Signature(); sig != "Summary(count int, files int, folders int) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+	for _, expected := range []string{
+		"case files == 1:",
+		"case folders == 1:",
+		"case count == 1:",
+		`"%s file%s in %s %s, %s item%s", formatInt(files, ",", 1, 3), plural0, formatInt(folders, ",", 1, 3), plural1, formatInt(count, ",", 1, 3), plural2`,
+	} {
+		if !strings.Contains(trFunc.Body, expected) {
+			t.Errorf("Expected body to contain %q, got:\n%s", expected, trFunc.Body)
+		}
+	}
+
+	result = parseContent("en.toml", "en", "apples = \"{{apples: one: apple, other: apples}}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	apples := result.root["apples"]
+	if sig := apples.Signature(); sig != "Apples(apples int) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+}
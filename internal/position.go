@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// valueLocation is where the string value of a key starts in a TOML file.
+type valueLocation struct {
+	Start     int    // Byte offset of the first character after the opening delimiter
+	Delimiter string // One of ", ', """ or '''
+}
+
+// locateValues finds the string values in a TOML file, keyed by their dotted key path (e.g. "menu.title").
+// BurntSushi/toml keeps key positions to itself, so this does a light-weight pass over the file. It only
+// needs to understand as much TOML as translation files use, and values it can't find are reported without
+// a position.
+func locateValues(tomlData string) map[string]valueLocation {
+	locations := make(map[string]valueLocation)
+	var table []string
+	i := 0
+	n := len(tomlData)
+
+	skipToLineEnd := func() {
+		for i < n && tomlData[i] != '\n' {
+			i++
+		}
+	}
+
+	for i < n {
+		// Start of a line
+		for i < n && (tomlData[i] == ' ' || tomlData[i] == '\t' || tomlData[i] == '\r' || tomlData[i] == '\n') {
+			i++
+		}
+		if i >= n {
+			break
+		}
+
+		switch tomlData[i] {
+		case '#':
+			skipToLineEnd()
+			continue
+		case '[':
+			end := strings.IndexByte(tomlData[i:], ']')
+			if end < 0 {
+				return locations
+			}
+			header := strings.Trim(tomlData[i:i+end], "[] \t")
+			table = splitKey(header)
+			i += end
+			skipToLineEnd()
+			continue
+		}
+
+		eq := strings.IndexByte(tomlData[i:], '=')
+		lineEnd := strings.IndexByte(tomlData[i:], '\n')
+		if eq < 0 || (lineEnd >= 0 && lineEnd < eq) {
+			skipToLineEnd()
+			continue
+		}
+		key := append(append([]string{}, table...), splitKey(tomlData[i:i+eq])...)
+		i += eq + 1
+		for i < n && (tomlData[i] == ' ' || tomlData[i] == '\t') {
+			i++
+		}
+
+		delimiter := ""
+		for _, candidate := range []string{`"""`, `'''`, `"`, `'`} {
+			if strings.HasPrefix(tomlData[i:], candidate) {
+				delimiter = candidate
+				break
+			}
+		}
+		if delimiter == "" {
+			skipToLineEnd()
+			continue
+		}
+		i += len(delimiter)
+		locations[strings.Join(key, ".")] = valueLocation{Start: i, Delimiter: delimiter}
+
+		// Skip past the closing delimiter
+		for i < n && !strings.HasPrefix(tomlData[i:], delimiter) {
+			if tomlData[i] == '\\' && delimiter[0] == '"' {
+				i++
+			}
+			i++
+		}
+		i += len(delimiter)
+		skipToLineEnd()
+	}
+	return locations
+}
+
+// splitKey splits a dotted TOML key, unquoting its parts.
+func splitKey(key string) []string {
+	parts := make([]string, 0)
+	var part strings.Builder
+	quote := byte(0)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			part.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		case c != ' ' && c != '\t':
+			part.WriteByte(c)
+		}
+	}
+	return append(parts, part.String())
+}
+
+// rawOffset maps an offset into the decoded value to a byte offset into the TOML file, by
+// stepping over escape sequences and the newline that multi-line strings may start with.
+func (l valueLocation) rawOffset(tomlData string, decodedOffset int) int {
+	i := l.Start
+	multiline := len(l.Delimiter) == 3
+	basic := l.Delimiter[0] == '"'
+	if multiline && strings.HasPrefix(tomlData[i:], "\r\n") {
+		i += 2
+	} else if multiline && strings.HasPrefix(tomlData[i:], "\n") {
+		i++
+	}
+
+	decoded := 0
+	for i < len(tomlData) && decoded < decodedOffset {
+		if !basic || tomlData[i] != '\\' || i+1 >= len(tomlData) {
+			i++
+			decoded++
+			continue
+		}
+		switch next := tomlData[i+1]; next {
+		case 'u', 'U':
+			length := 4
+			if next == 'U' {
+				length = 8
+			}
+			if i+2+length > len(tomlData) {
+				return i
+			}
+			r, err := strconv.ParseUint(tomlData[i+2:i+2+length], 16, 32)
+			if err != nil {
+				return i
+			}
+			i += 2 + length
+			decoded += utf8.RuneLen(rune(r))
+		case ' ', '\t', '\r', '\n':
+			// Line ending backslash, which trims all whitespace up to the next text
+			i++
+			for i < len(tomlData) && strings.ContainsRune(" \t\r\n", rune(tomlData[i])) {
+				i++
+			}
+		default:
+			i += 2
+			decoded++
+		}
+	}
+	return i
+}
+
+// lineAndColumn returns the 1-based line and column (in characters) of a byte offset.
+func lineAndColumn(tomlData string, offset int) (int, int) {
+	before := tomlData[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCountInString(before[lineStart:]) + 1
+}
+
+// snippet returns the line of the offset, with a caret pointing at the offset.
+func snippet(tomlData string, offset int) string {
+	lineStart := strings.LastIndexByte(tomlData[:offset], '\n') + 1
+	lineEnd := strings.IndexByte(tomlData[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(tomlData)
+	} else {
+		lineEnd += offset
+	}
+	line := strings.TrimRight(tomlData[lineStart:lineEnd], "\r")
+
+	// Keep tabs in the padding so the caret lines up with the line above
+	var padding strings.Builder
+	for _, r := range tomlData[lineStart:offset] {
+		if r == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+	return fmt.Sprintf("    %s\n    %s^", line, padding.String())
+}
+
+// diagnostic formats err as `file:line:col: message`, followed by a snippet of the offending line.
+func diagnostic(filename string, tomlData string, offset int, err error) error {
+	line, column := lineAndColumn(tomlData, offset)
+	return fmt.Errorf("%s:%d:%d: %w\n%s", filename, line, column, err, snippet(tomlData, offset))
+}
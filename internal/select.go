@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const selectOther = "other"
+
+type selectOption struct {
+	Key  string
+	Text string
+}
+
+// selectBlock is the content of a select, e.g. `{gender, select, female {Sie} male {Er} other {Sie}}`.
+type selectBlock struct {
+	Param   string
+	Options []selectOption // In order of appearance
+}
+
+func parseSelect(value string) (selectBlock, error) {
+	header := selectHeaderRegexp.FindString(value)
+	param, _, _ := strings.Cut(header, ",")
+	block := selectBlock{Param: strings.TrimSpace(param)}
+
+	seen := make(map[string]bool)
+	rest := value[len(header):]
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			break
+		}
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			return selectBlock{}, fmt.Errorf("expected '{' after '%s' in select on '%s'", rest, block.Param)
+		}
+		key := strings.TrimSpace(rest[:open])
+		if key == "" || strings.ContainsAny(key, " \t\r\n}") {
+			return selectBlock{}, fmt.Errorf("expected a single option name before '{' in select on '%s', but found '%s'", block.Param, key)
+		}
+		end := strings.IndexByte(rest[open+1:], '}')
+		if end < 0 {
+			return selectBlock{}, fmt.Errorf("missing end '}' for option '%s' in select on '%s'", key, block.Param)
+		}
+		text := rest[open+1 : open+1+end]
+		if strings.ContainsRune(text, '{') {
+			return selectBlock{}, fmt.Errorf("option '%s' in select on '%s' can only contain text", key, block.Param)
+		}
+		if seen[key] {
+			return selectBlock{}, fmt.Errorf("duplicate option '%s' in select on '%s'", key, block.Param)
+		}
+		seen[key] = true
+		block.Options = append(block.Options, selectOption{Key: key, Text: text})
+		rest = rest[open+1+end+1:]
+	}
+
+	if !seen[selectOther] {
+		return selectBlock{}, fmt.Errorf("select on '%s' is missing the '%s' option", block.Param, selectOther)
+	}
+	return block, nil
+}
+
+// selectOptions returns the sorted option keys of every parameter used in a select.
+func (t *TranslateFunc) selectOptions() map[string][]string {
+	keysByParam := make(map[string]map[string]bool)
+	for _, seg := range t.segments {
+		if seg.Type != segmentSelect {
+			continue
+		}
+		if keysByParam[seg.Param] == nil {
+			keysByParam[seg.Param] = make(map[string]bool)
+		}
+		for _, option := range seg.Select.Options {
+			keysByParam[seg.Param][option.Key] = true
+		}
+	}
+
+	optionsByParam := make(map[string][]string)
+	for param, keys := range keysByParam {
+		for key := range keys {
+			optionsByParam[param] = append(optionsByParam[param], key)
+		}
+		sort.Strings(optionsByParam[param])
+	}
+	return optionsByParam
+}
@@ -1,5 +1,10 @@
 package internal
 
+import (
+	"regexp"
+	"strings"
+)
+
 type Token struct {
 	Type  TokenType
 	Value string
@@ -14,13 +19,18 @@ const (
 	TokenText TokenType = iota
 	TokenSub
 	TokenPlural
+	TokenSelect
 )
 
+// selectHeaderRegexp matches the start of a select, e.g. `{gender, select, female {Sie} other {Sie}}`
+var selectHeaderRegexp = regexp.MustCompile(`^\s*[A-Za-z_][A-Za-z0-9_]*\s*,\s*select\s*,`)
+
 func tokenize(input string) []Token {
 	tokens := make([]Token, 0)
 	i := 0
 	tokenStart := 0
 	curType := TokenText
+	selectDepth := 0 // Number of open option braces in a select
 
 	peek := func() byte {
 		if i+1 < len(input) {
@@ -35,7 +45,10 @@ func tokenize(input string) []Token {
 			return // no content
 		}
 		value := input[tokenStart:tokenEnd]
-		if curType == TokenSub {
+		if curType == TokenText {
+			value = unescapeText(value)
+		}
+		if curType == TokenSub || curType == TokenSelect {
 			if isPremature {
 				errorMessage = "missing end '}'"
 				value = input[tokenStart+1 : tokenEnd]
@@ -62,6 +75,11 @@ func tokenize(input string) []Token {
 
 	for i < len(input) {
 		c := input[i]
+		if c == '\\' && curType == TokenText && isEscapable(peek()) {
+			i += 2 // skip the escaped character
+			continue
+		}
+
 		if c == '{' {
 			if curType == TokenText {
 				endToken(TokenText, i, false)
@@ -72,6 +90,11 @@ func tokenize(input string) []Token {
 				} else {
 					curType = TokenSub
 				}
+			} else if curType == TokenSub && selectHeaderRegexp.MatchString(input[tokenStart+1:i]) {
+				curType = TokenSelect
+				selectDepth = 1
+			} else if curType == TokenSelect {
+				selectDepth++
 			}
 		}
 
@@ -80,6 +103,14 @@ func tokenize(input string) []Token {
 				endToken(TokenSub, i+1, false)
 				tokenStart = i + 1
 				curType = TokenText
+			} else if curType == TokenSelect {
+				if selectDepth > 0 {
+					selectDepth--
+				} else {
+					endToken(TokenSelect, i+1, false)
+					tokenStart = i + 1
+					curType = TokenText
+				}
 			} else if curType == TokenPlural {
 				if peek() == '}' {
 					endToken(TokenPlural, i+2, false)
@@ -100,3 +131,19 @@ func tokenize(input string) []Token {
 
 	return tokens
 }
+
+// Characters that can be escaped with a backslash in text, e.g. `\{` for a literal '{'.
+func isEscapable(c byte) bool {
+	return c == '{' || c == '}' || c == '\\'
+}
+
+func unescapeText(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && isEscapable(text[i+1]) {
+			i++
+		}
+		sb.WriteByte(text[i])
+	}
+	return sb.String()
+}
@@ -111,6 +111,60 @@ func TestTokenize(t *testing.T) {
 				{Type: TokenPlural, Value: "", Start: 4, End: 8},
 			},
 		},
+		{
+			name:  "select",
+			input: "{gender, select, female {Sie} other {Er}} kommt",
+			expected: []Token{
+				{Type: TokenSelect, Value: "gender, select, female {Sie} other {Er}", Start: 0, End: 41},
+				{Type: TokenText, Value: " kommt", Start: 41, End: 47},
+			},
+		},
+		{
+			name:  "select after substitution",
+			input: "{name}: {g,select,other {}}",
+			expected: []Token{
+				{Type: TokenSub, Value: "name", Start: 0, End: 6},
+				{Type: TokenText, Value: ": ", Start: 6, End: 8},
+				{Type: TokenSelect, Value: "g,select,other {}", Start: 8, End: 27},
+			},
+		},
+		{
+			name:  "bad case: no closing } for select",
+			input: "{gender, select, other {Sie}",
+			expected: []Token{
+				{Type: TokenSelect, Value: "gender, select, other {Sie}", Start: 0, End: 28, Error: "missing end '}'"},
+			},
+		},
+		{
+			name:  "escaped braces",
+			input: `Use \{name\} for {what}`,
+			expected: []Token{
+				{Type: TokenText, Value: "Use {name} for ", Start: 0, End: 17},
+				{Type: TokenSub, Value: "what", Start: 17, End: 23},
+			},
+		},
+		{
+			name:  "escaped plural braces",
+			input: `\{\{s\}\}`,
+			expected: []Token{
+				{Type: TokenText, Value: "{{s}}", Start: 0, End: 9},
+			},
+		},
+		{
+			name:  "escaped backslash before substitution",
+			input: `C:\\{dir}`,
+			expected: []Token{
+				{Type: TokenText, Value: `C:\`, Start: 0, End: 4},
+				{Type: TokenSub, Value: "dir", Start: 4, End: 9},
+			},
+		},
+		{
+			name:  "backslash without escapable character",
+			input: `a\b`,
+			expected: []Token{
+				{Type: TokenText, Value: `a\b`, Start: 0, End: 3},
+			},
+		},
 		{
 			name:  "bad case: nested substitutions",
 			input: "{name{nested}}",
@@ -186,6 +240,8 @@ func presentTokenType(tokenType TokenType) string {
 		return "Substitution"
 	case TokenPlural:
 		return "Plural"
+	case TokenSelect:
+		return "Select"
 	default:
 		return "Unknown"
 	}
@@ -1,40 +1,144 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+	"golang.org/x/text/language"
 )
 
 type TomlParseResult struct {
-	Locale string
+	Locale string // Locale as used in file names and identifiers, e.g. "zh_hant_tw"
+	Tag    string // Canonical BCP 47 tag of the locale, e.g. "zh-Hant-TW"
 	Errors []error
+	// Fallback is the locale that missing translations are delegated to, for regional locales
+	Fallback string
 
 	root     map[string]TranslateFunc
-	sections map[string]map[string]TranslateFunc
+	sections map[string]translationSection
+}
+
+// translationSection is a TOML table of translations, e.g. [settings], which can have nested sections
+// of its own, e.g. [settings.account].
+type translationSection struct {
+	funcs    map[string]TranslateFunc
+	sections map[string]translationSection
+}
+
+// Keys returns the keys of all translations, e.g. "menu.title".
+func (r TomlParseResult) Keys() []string {
+	return r.keysWhere(func(trFunc TranslateFunc) bool {
+		return true
+	})
+}
+
+// InheritedKeys returns the keys of the translations that are delegated to the fallback locale, e.g. "menu.title".
+func (r TomlParseResult) InheritedKeys() []string {
+	return r.keysWhere(func(trFunc TranslateFunc) bool {
+		return trFunc.Fallback != "" && !trFunc.Missing
+	})
+}
+
+// MissingKeys returns the keys of the translations that are missing, and delegated to the base locale.
+func (r TomlParseResult) MissingKeys() []string {
+	return r.keysWhere(func(trFunc TranslateFunc) bool {
+		return trFunc.Missing
+	})
+}
+
+func (r TomlParseResult) keysWhere(include func(trFunc TranslateFunc) bool) []string {
+	keys := make([]string, 0)
+	var collect func(prefix string, funcs map[string]TranslateFunc, sections map[string]translationSection)
+	collect = func(prefix string, funcs map[string]TranslateFunc, sections map[string]translationSection) {
+		for key, trFunc := range funcs {
+			if include(trFunc) {
+				keys = append(keys, prefix+key)
+			}
+		}
+		for key, section := range sections {
+			collect(prefix+key+".", section.funcs, section.sections)
+		}
+	}
+	collect("", r.root, r.sections)
+	sort.Strings(keys)
+	return keys
+}
+
+// allFuncs returns the translations of the root and all sections.
+func (r TomlParseResult) allFuncs() []TranslateFunc {
+	var result []TranslateFunc
+	var collect func(funcs map[string]TranslateFunc, sections map[string]translationSection)
+	collect = func(funcs map[string]TranslateFunc, sections map[string]translationSection) {
+		for _, trFunc := range funcs {
+			result = append(result, trFunc)
+		}
+		for _, section := range sections {
+			collect(section.funcs, section.sections)
+		}
+	}
+	collect(r.root, r.sections)
+	return result
 }
 
 type ProcessedLocale struct {
 	BaseLocale          string
 	ParsedFuncsByLocale map[string]TomlParseResult
+	// Aliases by their canonical tags, to the locale that they're aliases of, e.g. "no" to "nb"
+	Aliases map[string]string
 }
 
-func ProcessTomlDir(tomlDir string, baseLocale string) (ProcessedLocale, error) {
-	// Be case-insensitive since we're dealing with locales based on filenames
-	localeRegexp, err := regexp.Compile(`^[a-z]{2}(_[a-z]{2})?$`)
-	if err != nil {
-		return ProcessedLocale{}, fmt.Errorf("failed to compile locale regex: %w", err)
+type ProcessOptions struct {
+	BaseLocale string
+	// Locales that may be missing translations, which then fall back to the base locale. "all" allows it
+	// for all locales.
+	AllowMissing []string
+	// Patterns of file names to ignore in the TOML dir, like "*_draft.toml", see filepath.Match
+	Exclude []string
+	// The locale that a locale falls back to for missing translations, instead of the default (e.g. "de_at" to
+	// "de_de" instead of "de"). The fallback can have a fallback of its own, which makes a chain.
+	Fallbacks map[string]string
+	// Other locales to use the translations of a locale for, e.g. "no" for "nb"
+	Aliases map[string]string
+}
+
+func (o ProcessOptions) allowsMissing(locale string) bool {
+	for _, allowed := range o.AllowMissing {
+		if allowed == "all" {
+			return true
+		}
+		if _, allowedLocale, err := canonicalLocale(allowed); err == nil && allowedLocale == locale {
+			return true
+		}
 	}
+	return false
+}
 
-	if !localeRegexp.MatchString(baseLocale) {
-		return ProcessedLocale{}, fmt.Errorf("invalid base locale: %s (expected format 'xx' or 'xx_xx')", baseLocale)
+func ProcessTomlDir(tomlDir string, options ProcessOptions) (ProcessedLocale, error) {
+	baseLocale := ""
+	if options.BaseLocale != "" {
+		var err error
+		if _, baseLocale, err = canonicalLocale(options.BaseLocale); err != nil {
+			return ProcessedLocale{}, fmt.Errorf("invalid base locale: %w", err)
+		}
 	}
 
-	files, err := filepath.Glob(filepath.Join(tomlDir, "*.toml"))
+	fallbacks := make(map[string]string, len(options.Fallbacks))
+	for locale, fallback := range options.Fallbacks {
+		_, canonical, err := canonicalLocale(locale)
+		if err != nil {
+			return ProcessedLocale{}, fmt.Errorf("invalid fallback: %w", err)
+		}
+		if _, fallbacks[canonical], err = canonicalLocale(fallback); err != nil {
+			return ProcessedLocale{}, fmt.Errorf("invalid fallback for %s: %w", locale, err)
+		}
+	}
+
+	files, err := FindTranslationFiles(tomlDir, options.Exclude)
 	if err != nil {
 		return ProcessedLocale{}, err
 	}
@@ -44,43 +148,34 @@ func ProcessTomlDir(tomlDir string, baseLocale string) (ProcessedLocale, error)
 
 	parsedTomlByLocale := make(map[string]TomlParseResult)
 
-	seenLocales := make(map[string]bool)
 	errorsByFile := make(map[string][]error)
 	for _, file := range files {
-		filename := filepath.Base(file)
-		locale := strings.ToLower(strings.TrimSuffix(filename, ".toml"))
-		if !localeRegexp.MatchString(locale) {
-			fmt.Fprintf(os.Stderr, "ignoring file %s (filename maps to locale '%s', only accepting forms 'xx' or 'xx_xx')\n", file, locale)
-			continue
-		}
-		if seenLocales[locale] {
-			fmt.Fprintf(os.Stderr, "ignoring duplicate locale %s from file %s\n", locale, file)
-			continue
-		}
-		seenLocales[locale] = true
+		filename := filepath.Base(file.Path)
 		if baseLocale == "" {
-			baseLocale = locale
+			baseLocale = file.Locale
 		}
-		fileData, err := os.ReadFile(file)
+		fileData, err := os.ReadFile(file.Path)
 		if err != nil {
-			errorsByFile[filename] = append(errorsByFile[filename], fmt.Errorf("failed to read file %s: %w", file, err))
+			errorsByFile[filename] = append(errorsByFile[filename], fmt.Errorf("failed to read file %s: %w", file.Path, err))
 			continue
 		}
 
-		parsedToml := parseContent(locale, string(fileData))
+		parsedToml := parseContent(filename, file.Locale, string(fileData))
+		parsedToml.Tag = file.Tag
 		if len(parsedToml.Errors) > 0 {
 			for _, err := range parsedToml.Errors {
 				errorsByFile[filename] = append(errorsByFile[filename], err)
 			}
 			continue
 		}
-		parsedTomlByLocale[locale] = parsedToml
+		parsedTomlByLocale[file.Locale] = parsedToml
 	}
 
 	if len(errorsByFile) > 0 {
 		// Bail early, it doesn't make sense to validate the file structures until they have the correct syntax
 		var errorMsg strings.Builder
-		for file, errors := range errorsByFile {
+		for _, file := range getKeysSorted(errorsByFile) {
+			errors := errorsByFile[file]
 			errorMsg.WriteString(fmt.Sprintf("%s (%d errors)\n", file, len(errors)))
 			for _, e := range errors {
 				errorMsg.WriteString(fmt.Sprintf("\t- %s\n", e))
@@ -90,11 +185,12 @@ func ProcessTomlDir(tomlDir string, baseLocale string) (ProcessedLocale, error)
 		return ProcessedLocale{}, fmt.Errorf("%s", errorMsg.String())
 	}
 
-	if errors := validateAllLocales(baseLocale, parsedTomlByLocale); len(errors) != 0 {
-		for locale, errors := range errors {
+	if errors := validateAllLocales(baseLocale, parsedTomlByLocale, fallbacks, options.allowsMissing); len(errors) != 0 {
+		for _, locale := range getKeysSorted(errors) {
+			localeErrors := errors[locale]
 			var errorMsg strings.Builder
-			errorMsg.WriteString(fmt.Sprintf("found %d validation errors", len(errors)))
-			for _, err := range errors {
+			errorMsg.WriteString(fmt.Sprintf("found %d validation errors", len(localeErrors)))
+			for _, err := range localeErrors {
 				errorMsg.WriteString("\n- ")
 				errorMsg.WriteString(err.Error())
 			}
@@ -102,9 +198,26 @@ func ProcessTomlDir(tomlDir string, baseLocale string) (ProcessedLocale, error)
 		}
 	}
 
+	for _, locale := range getKeysSorted(parsedTomlByLocale) {
+		// Regional locales without a parent inherit from the base locale, which may not even be the same language
+		tomlData := parsedTomlByLocale[locale]
+		if _, configured := fallbacks[locale]; tomlData.Fallback == baseLocale && !configured && !isParentLocale(baseLocale, locale) {
+			if inherited := tomlData.InheritedKeys(); len(inherited) > 0 {
+				fmt.Fprintf(os.Stderr, "warning: %s has no parent locale, so it inherits %d translations from the base locale %s: %s\n", locale, len(inherited), baseLocale, strings.Join(inherited, ", "))
+			}
+		}
+		if _, known := dateConventionsFor(locale); usesDates(tomlData) && !known {
+			fmt.Fprintf(os.Stderr, "warning: %s has no known date formats, so its dates and times are formatted like ISO 8601, e.g. 2024-03-05 14:30\n", locale)
+		}
+		for _, key := range parsedTomlByLocale[locale].MissingKeys() {
+			fmt.Fprintf(os.Stderr, "warning: %s is missing translation '%s', falling back to %s\n", locale, key, baseLocale)
+		}
+	}
+
 	if len(errorsByFile) > 0 {
 		var sb strings.Builder
-		for file, errors := range errorsByFile {
+		for _, file := range getKeysSorted(errorsByFile) {
+			errors := errorsByFile[file]
 			sb.WriteString(fmt.Sprintf("\n%s", file))
 			for _, err := range errors {
 				sb.WriteString(fmt.Sprintf("\n - %s", err))
@@ -113,32 +226,107 @@ func ProcessTomlDir(tomlDir string, baseLocale string) (ProcessedLocale, error)
 		return ProcessedLocale{}, fmt.Errorf("%s", sb.String())
 	}
 
+	aliases, err := resolveAliases(options.Aliases, parsedTomlByLocale)
+	if err != nil {
+		return ProcessedLocale{}, err
+	}
+
 	return ProcessedLocale{
 		BaseLocale:          baseLocale,
 		ParsedFuncsByLocale: parsedTomlByLocale,
+		Aliases:             aliases,
 	}, nil
 }
 
+// resolveAliases returns the aliases by their canonical tags, to the locales they're aliases of.
+func resolveAliases(aliases map[string]string, localeToData map[string]TomlParseResult) (map[string]string, error) {
+	resolved := make(map[string]string, len(aliases))
+	for _, alias := range getKeysSorted(aliases) {
+		aliasTag, aliasLocale, err := canonicalLocale(alias)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias: %w", err)
+		}
+		_, locale, err := canonicalLocale(aliases[alias])
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale for alias %s: %w", alias, err)
+		}
+		if _, exists := localeToData[aliasLocale]; exists {
+			return nil, fmt.Errorf("alias %s is already a locale", alias)
+		}
+		if _, exists := localeToData[locale]; !exists {
+			return nil, fmt.Errorf("alias %s is for %s, which doesn't exist", alias, aliases[alias])
+		}
+		resolved[aliasTag] = locale
+	}
+	return resolved, nil
+}
+
 var prohibitedNames = map[string]bool{
-	"SetLanguage":   true,
-	"NewTranslator": true,
+	"SetLanguage":           true,
+	"SetLanguageFromHeader": true,
+	"NewTranslator":         true,
+	"Lookup":                true,
 }
 
-func parseContent(locale string, tomlData string) TomlParseResult {
+func parseContent(filename string, locale string, tomlData string) TomlParseResult {
 	data := TomlParseResult{
 		Locale:   locale,
 		Errors:   make([]error, 0),
 		root:     make(map[string]TranslateFunc),
-		sections: make(map[string]map[string]TranslateFunc),
+		sections: make(map[string]translationSection),
 	}
 
+	conventions := conventionsFor(locale)
+
 	var tomlContent map[string]any
 	if _, err := toml.Decode(tomlData, &tomlContent); err != nil {
-		data.Errors = append(data.Errors, fmt.Errorf("failed to decode TOML content: %w", err))
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) && parseErr.Position.Start <= len(tomlData) {
+			err = diagnostic(filename, tomlData, parseErr.Position.Start, fmt.Errorf("failed to decode TOML content: %s", parseErr.Message))
+		} else {
+			err = fmt.Errorf("%s: failed to decode TOML content: %w", filename, err)
+		}
+		data.Errors = append(data.Errors, err)
 		return data // fatal
 	}
 
-	for k := range tomlContent {
+	// Point errors in translations to where they are in the file
+	locations := locateValues(tomlData)
+	locateError := func(keyPath string, err error) error {
+		var tmplErr *templateError
+		location, found := locations[keyPath]
+		if !found || !errors.As(err, &tmplErr) {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		return diagnostic(filename, tomlData, location.rawOffset(tomlData, tmplErr.Offset), tmplErr.Err)
+	}
+
+	var parseSection func(path []string, table map[string]any) translationSection
+	parseSection = func(path []string, table map[string]any) translationSection {
+		section := translationSection{
+			funcs:    make(map[string]TranslateFunc),
+			sections: make(map[string]translationSection),
+		}
+		for _, key := range getKeysSorted(table) {
+			value := table[key]
+			switch value := value.(type) {
+			case string:
+				trFunc, err := parseTranslateFunc(key, value, conventions)
+				if err != nil {
+					data.Errors = append(data.Errors, locateError(strings.Join(append(path, key), "."), err))
+				} else {
+					section.funcs[key] = trFunc
+				}
+			case map[string]any:
+				section.sections[key] = parseSection(append(path[:len(path):len(path)], key), value)
+			default:
+				data.Errors = append(data.Errors, fmt.Errorf("expected string under %s > %s, but found '%v'", strings.Join(path, " > "), key, value))
+			}
+		}
+		return section
+	}
+
+	for _, k := range getKeysSorted(tomlContent) {
 		generatedName := toPublicName(k)
 		if prohibitedNames[generatedName] {
 			data.Errors = append(data.Errors, fmt.Errorf("'%s' conflicts with '%s' and cannot be used as translation key", k, generatedName))
@@ -148,9 +336,9 @@ func parseContent(locale string, tomlData string) TomlParseResult {
 		// Root entries
 		entry := tomlContent[k]
 		if val, ok := entry.(string); ok {
-			trFunc, err := parseTranslateFunc(k, val)
+			trFunc, err := parseTranslateFunc(k, val, conventions)
 			if err != nil {
-				data.Errors = append(data.Errors, err)
+				data.Errors = append(data.Errors, locateError(k, err))
 			} else {
 				data.root[k] = trFunc
 			}
@@ -158,21 +346,8 @@ func parseContent(locale string, tomlData string) TomlParseResult {
 		}
 
 		// Sections
-		if section, ok := entry.(map[string]any); ok {
-			sectionFuncs := make(map[string]TranslateFunc)
-			for sectionKey, sectionVal := range section {
-				if strVal, ok := sectionVal.(string); ok {
-					trFunc, err := parseTranslateFunc(sectionKey, strVal)
-					if err != nil {
-						data.Errors = append(data.Errors, err)
-					} else {
-						sectionFuncs[sectionKey] = trFunc
-					}
-				} else {
-					data.Errors = append(data.Errors, fmt.Errorf("expected string under %s > %s, but found '%v'", k, sectionKey, sectionVal))
-				}
-			}
-			data.sections[k] = sectionFuncs
+		if table, ok := entry.(map[string]any); ok {
+			data.sections[k] = parseSection([]string{k}, table)
 			continue
 		}
 
@@ -191,21 +366,48 @@ func validateSection(baseMap, otherMap map[string]TranslateFunc, sectionName str
 		return fmt.Sprintf("[%s]: %s", sectionName, key)
 	}
 
-	for key, baseFunc := range baseMap {
+	for _, key := range getKeysSorted(baseMap) {
+		baseFunc := baseMap[key]
 		otherFunc, exists := otherMap[key]
 		if !exists {
 			errors = append(errors, fmt.Errorf("%s is missing translation '%s'", otherLocale, keyName(key)))
+			continue
+		}
+		if otherFunc.Fallback != "" {
+			continue // Validated in the fallback locale
 		}
 
+		// Substitutions that aren't annotated in other locales use the annotations of the base locale
+		otherFunc.inheritAnnotations(baseFunc)
+		otherMap[key] = otherFunc
+
 		baseSig := baseFunc.Signature()
 		otherSig := otherFunc.Signature()
 
 		if baseSig != otherSig {
-			errors = append(errors, fmt.Errorf("%s has the wrong signature for '%s'. Should be `%s`, but was `%s`", otherLocale, keyName(key), baseSig, otherSig))
+			errors = append(errors, fmt.Errorf("%s has the wrong signature for '%s'. Should be `%s`, but was `%s`%s", otherLocale, keyName(key), baseSig, otherSig, paramTypeHint(baseFunc, otherFunc)))
+		}
+
+		// Selects must cover the same options in all locales
+		baseOptions := baseFunc.selectOptions()
+		otherOptions := otherFunc.selectOptions()
+		selectParams := make(map[string]bool)
+		for param := range baseOptions {
+			selectParams[param] = true
+		}
+		for param := range otherOptions {
+			selectParams[param] = true
+		}
+		for _, param := range getKeysSorted(selectParams) {
+			expected := strings.Join(baseOptions[param], ", ")
+			actual := strings.Join(otherOptions[param], ", ")
+			if expected != actual {
+				errors = append(errors, fmt.Errorf("%s has the wrong select options for '%s' in '%s'. Should be [%s], but was [%s]", otherLocale, param, keyName(key), expected, actual))
+			}
 		}
 	}
 
-	for key := range otherMap {
+	for _, key := range getKeysSorted(otherMap) {
 		if _, exists := baseMap[key]; !exists {
 			errors = append(errors, fmt.Errorf("%s has an unknown translation '%s'", otherLocale, keyName(key)))
 		}
@@ -213,7 +415,28 @@ func validateSection(baseMap, otherMap map[string]TranslateFunc, sectionName str
 	return errors
 }
 
-func validateAllLocales(baseLocale string, localeToData map[string]TomlParseResult) map[string][]error {
+// paramTypeHint explains parameters that are used with different types, which typically happens when
+// a parameter is annotated, or drives a plural block, in only one of the locales.
+func paramTypeHint(baseFunc, otherFunc TranslateFunc) string {
+	baseTypes := make(map[string]string)
+	for _, param := range baseFunc.Params {
+		baseTypes[param.Name] = param.Type
+	}
+	var hints []string
+	for _, param := range otherFunc.Params {
+		if baseType, exists := baseTypes[param.Name]; exists && baseType != param.Type {
+			hints = append(hints, fmt.Sprintf("'%s' is %s in the base locale but %s here", param.Name, baseType, param.Type))
+		}
+	}
+	if len(hints) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(hints, ", ") + ")"
+}
+
+// validateAllLocales validates all locales against the base locale. Translations that are missing in locales
+// that allowMissing accepts are delegated to the base locale instead.
+func validateAllLocales(baseLocale string, localeToData map[string]TomlParseResult, fallbacks map[string]string, allowMissing func(locale string) bool) map[string][]error {
 	errors := make(map[string][]error)
 	baseLocaleData, ok := localeToData[baseLocale]
 	if !ok {
@@ -221,10 +444,55 @@ func validateAllLocales(baseLocale string, localeToData map[string]TomlParseResu
 		return errors // critical error
 	}
 
-	for otherLocale, otherLocaleData := range localeToData {
-		if otherLocale == baseLocale {
+	// Locales are validated after their fallbacks (e.g. zh_hant_tw after zh_hant), since the translations they're
+	// missing are copied from their fallbacks, which must have inherited the types of the base locale first.
+	otherLocales := make([]string, 0, len(localeToData))
+	depths := make(map[string]int)
+	for _, locale := range getKeysSorted(localeToData) {
+		if locale == baseLocale {
 			continue
 		}
+		otherLocales = append(otherLocales, locale)
+		seen := map[string]bool{locale: true}
+		for fallback := fallbackLocale(locale, baseLocale, localeToData, fallbacks); fallback != ""; fallback = fallbackLocale(fallback, baseLocale, localeToData, fallbacks) {
+			if _, exists := localeToData[fallback]; !exists {
+				errors[locale] = append(errors[locale], fmt.Errorf("%s falls back to %s, which doesn't exist", locale, fallback))
+				break
+			}
+			if seen[fallback] {
+				errors[locale] = append(errors[locale], fmt.Errorf("%s has a cycle of fallbacks through %s", locale, fallback))
+				break
+			}
+			seen[fallback] = true
+			depths[locale]++
+		}
+	}
+	if len(errors) != 0 {
+		return errors // critical error
+	}
+	if fallback, exists := fallbacks[baseLocale]; exists {
+		errors[baseLocale] = append(errors[baseLocale], fmt.Errorf("base locale %s can't fall back to %s", baseLocale, fallback))
+		return errors
+	}
+	sort.SliceStable(otherLocales, func(i, j int) bool {
+		return depths[otherLocales[i]] < depths[otherLocales[j]]
+	})
+
+	for _, otherLocale := range otherLocales {
+		otherLocaleData := localeToData[otherLocale]
+		if fallback := fallbackLocale(otherLocale, baseLocale, localeToData, fallbacks); fallback != "" {
+			fallbackData := localeToData[fallback]
+			inheritMissing(fallback, nil,
+				translationSection{funcs: fallbackData.root, sections: fallbackData.sections},
+				translationSection{funcs: otherLocaleData.root, sections: otherLocaleData.sections}, false)
+			otherLocaleData.Fallback = fallback
+			localeToData[otherLocale] = otherLocaleData
+		}
+		if allowMissing(otherLocale) {
+			inheritMissing(baseLocale, nil,
+				translationSection{funcs: baseLocaleData.root, sections: baseLocaleData.sections},
+				translationSection{funcs: otherLocaleData.root, sections: otherLocaleData.sections}, true)
+		}
 
 		if sectionErrors := validateSection(baseLocaleData.root, otherLocaleData.root, "", otherLocale); len(sectionErrors) != 0 {
 			for _, err := range sectionErrors {
@@ -232,27 +500,128 @@ func validateAllLocales(baseLocale string, localeToData map[string]TomlParseResu
 			}
 		}
 
-		for sectionName, baseSection := range baseLocaleData.sections {
-			otherSection, exists := otherLocaleData.sections[sectionName]
-			if !exists {
-				errors[otherLocale] = append(errors[otherLocale], fmt.Errorf("%s is missing section [%s]", otherLocale, sectionName))
-				continue
-			}
+		if sectionErrors := validateSections(baseLocaleData.sections, otherLocaleData.sections, "", otherLocale); len(sectionErrors) != 0 {
+			errors[otherLocale] = append(errors[otherLocale], sectionErrors...)
+		}
+	}
 
-			if sectionErrors := validateSection(baseSection, otherSection, sectionName, otherLocale); len(sectionErrors) != 0 {
-				for _, err := range sectionErrors {
-					errMsg := fmt.Errorf("%s: %s", otherLocale, err.Error())
-					errors[otherLocale] = append(errors[otherLocale], errMsg)
-				}
-			}
+	return errors
+}
+
+// validateSections validates the sections of a locale against the base locale, including nested sections.
+// Sections are named by their TOML table names, e.g. [settings.account].
+func validateSections(baseSections, otherSections map[string]translationSection, parentName string, otherLocale string) []error {
+	errors := make([]error, 0)
+	sectionName := func(key string) string {
+		if parentName == "" {
+			return key
 		}
+		return parentName + "." + key
+	}
 
-		for sectionName := range otherLocaleData.sections {
-			if _, exists := baseLocaleData.sections[sectionName]; !exists {
-				errors[otherLocale] = append(errors[otherLocale], fmt.Errorf("%s has unknown section [%s]", otherLocale, sectionName))
-			}
+	for _, key := range getKeysSorted(baseSections) {
+		baseSection := baseSections[key]
+		otherSection, exists := otherSections[key]
+		if !exists {
+			errors = append(errors, fmt.Errorf("%s is missing section [%s]", otherLocale, sectionName(key)))
+			continue
 		}
+
+		for _, err := range validateSection(baseSection.funcs, otherSection.funcs, sectionName(key), otherLocale) {
+			errors = append(errors, fmt.Errorf("%s: %s", otherLocale, err.Error()))
+		}
+		errors = append(errors, validateSections(baseSection.sections, otherSection.sections, sectionName(key), otherLocale)...)
 	}
 
+	for _, key := range getKeysSorted(otherSections) {
+		if _, exists := baseSections[key]; !exists {
+			errors = append(errors, fmt.Errorf("%s has unknown section [%s]", otherLocale, sectionName(key)))
+		}
+	}
 	return errors
 }
+
+// fallbackLocale returns the locale that a regional locale falls back to for missing translations, e.g. en_uk
+// falls back to en if there is one, and otherwise to the base locale. Other locales have to be complete, unless
+// they have a configured fallback.
+func fallbackLocale(locale string, baseLocale string, localeToData map[string]TomlParseResult, fallbacks map[string]string) string {
+	if fallback, exists := fallbacks[locale]; exists && locale != baseLocale {
+		return fallback
+	}
+	if locale == baseLocale || !strings.Contains(locale, "_") {
+		return ""
+	}
+	for _, parent := range parentLocales(locale) {
+		if _, exists := localeToData[parent]; exists {
+			return parent
+		}
+	}
+	return baseLocale
+}
+
+func isParentLocale(parent string, locale string) bool {
+	for _, candidate := range parentLocales(locale) {
+		if candidate == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// parentLocales returns the locales that a regional locale can fall back to, closest first. Like the CLDR parent
+// locales, they never change the script, so zh_hant_tw and zh_tw fall back to zh_hant but not to zh, which is
+// written in Simplified Chinese, and sr_latn doesn't fall back to sr, which is written in Cyrillic.
+func parentLocales(locale string) []string {
+	script := localeScript(locale)
+	parents := make([]string, 0)
+	seen := map[string]bool{locale: true}
+	for parent := locale; strings.Contains(parent, "_"); {
+		parent = parent[:strings.LastIndex(parent, "_")]
+		candidates := []string{parent}
+		if !strings.Contains(parent, "_") && localeScript(parent) != script {
+			// The language in the script of the locale instead, e.g. zh_hant for zh_tw
+			candidates = []string{parent + "_" + strings.ToLower(script)}
+		}
+		for _, candidate := range candidates {
+			if !seen[candidate] && localeScript(candidate) == script {
+				parents = append(parents, candidate)
+			}
+			seen[candidate] = true
+		}
+	}
+	return parents
+}
+
+// localeScript returns the script that a locale is written in, e.g. "Hant" for zh_tw.
+func localeScript(locale string) string {
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return ""
+	}
+	script, _ := tag.Script()
+	return script.String()
+}
+
+// inheritMissing adds the translations and sections that are missing in a section, as delegates to the
+// same translations of the fallback locale. Translations are marked as missing when the fallback isn't
+// expected, but allowed.
+func inheritMissing(fallbackLocale string, path []string, fallback translationSection, section translationSection, missing bool) {
+	for key, trFunc := range fallback.funcs {
+		if _, exists := section.funcs[key]; !exists {
+			delegate := trFunc.delegateTo(fallbackLocale, path)
+			delegate.Missing = missing
+			section.funcs[key] = delegate
+		}
+	}
+	for key, fallbackSection := range fallback.sections {
+		child, exists := section.sections[key]
+		if !exists {
+			child = translationSection{
+				funcs:    make(map[string]TranslateFunc),
+				sections: make(map[string]translationSection),
+			}
+			section.sections[key] = child
+		}
+		inheritMissing(fallbackLocale, append(path[:len(path):len(path)], key), fallbackSection, child, missing)
+	}
+}
@@ -1,6 +1,9 @@
 package internal
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -25,10 +28,10 @@ func TestParseContent_ErrorCases(t *testing.T) {
 			errorContains: "unexpected type",
 		},
 		{
-			name:          "nested sections not supported",
-			toml:          "[foo.bar]\nkey = \"value\"",
+			name:          "non-string in nested section",
+			toml:          "[foo.bar]\nkey = 123",
 			expectError:   true,
-			errorContains: "expected string under foo > bar",
+			errorContains: "expected string under foo > bar > key",
 		},
 		{
 			name:          "prohibited name SetLanguage",
@@ -36,6 +39,18 @@ func TestParseContent_ErrorCases(t *testing.T) {
 			expectError:   true,
 			errorContains: "conflicts with 'SetLanguage'",
 		},
+		{
+			name:          "prohibited name SetLanguageFromHeader",
+			toml:          "set_language_from_header = \"test\"",
+			expectError:   true,
+			errorContains: "conflicts with 'SetLanguageFromHeader'",
+		},
+		{
+			name:          "prohibited name Lookup",
+			toml:          "lookup = \"test\"",
+			expectError:   true,
+			errorContains: "conflicts with 'Lookup'",
+		},
 		{
 			name:          "prohibited name NewTranslator",
 			toml:          "new_translator = \"test\"",
@@ -48,6 +63,60 @@ func TestParseContent_ErrorCases(t *testing.T) {
 			expectError:   true,
 			errorContains: "expected string under section > key",
 		},
+		{
+			name:          "unknown substitution type",
+			toml:          "price = \"{price:money}\"",
+			expectError:   true,
+			errorContains: "unexpected annotation 'money' for 'price'",
+		},
+		{
+			name:          "conflicting substitution types",
+			toml:          "price = \"{price:float} ({price:int})\"",
+			expectError:   true,
+			errorContains: "'price' is used both as float64 and int",
+		},
+		{
+			name:          "conflicting annotations",
+			toml:          "when = \"{when:date:time}\"",
+			expectError:   true,
+			errorContains: "conflicting annotation 'time' for 'when'",
+		},
+		{
+			name:          "width without date or time",
+			toml:          "price = \"{price:float:long}\"",
+			expectError:   true,
+			errorContains: "'long' only applies to date and time substitutions",
+		},
+		{
+			name:          "count with other type than int",
+			toml:          "items = \"{count:float} items\"",
+			expectError:   true,
+			errorContains: "'count' must be int",
+		},
+		{
+			name:          "select without other",
+			toml:          "greeting = \"{gender, select, female {Sie} male {Er}}\"",
+			expectError:   true,
+			errorContains: "select on 'gender' is missing the 'other' option",
+		},
+		{
+			name:          "select with duplicate option",
+			toml:          "greeting = \"{gender, select, male {Er} male {Er} other {Sie}}\"",
+			expectError:   true,
+			errorContains: "duplicate option 'male'",
+		},
+		{
+			name:          "select with substitution in option",
+			toml:          "greeting = \"{gender, select, male {Er {name}} other {Sie}}\"",
+			expectError:   true,
+			errorContains: "option 'male' in select on 'gender' can only contain text",
+		},
+		{
+			name:          "select on typed parameter",
+			toml:          "greeting = \"{gender:int} {gender, select, other {Sie}}\"",
+			expectError:   true,
+			errorContains: "'gender' is used both as int and string",
+		},
 		{
 			name:          "malformed substitution syntax",
 			toml:          "greeting = \"Hello {name\"",
@@ -64,7 +133,7 @@ func TestParseContent_ErrorCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseContent("en", tt.toml)
+			result := parseContent("en.toml", "en", tt.toml)
 
 			if tt.expectError {
 				if len(result.Errors) == 0 {
@@ -90,6 +159,492 @@ func TestParseContent_ErrorCases(t *testing.T) {
 	}
 }
 
+func TestParseContent_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		toml     string
+		expected string
+	}{
+		{
+			name:     "root key",
+			toml:     "title = \"Hello\"\nmessage = \"Hi {name\"",
+			expected: "sv.toml:2:15: syntax error: missing end '}'\n    message = \"Hi {name\"\n                  ^",
+		},
+		{
+			name:     "section key",
+			toml:     "[menu]\n# Comment\n  files = \"{{file|files}}{{\"",
+			expected: "sv.toml:3:26: syntax error: missing end '}}'",
+		},
+		{
+			name:     "after escapes",
+			toml:     "quote = \"\\\"{name}\\\" \\u00e4r {name:money}\"",
+			expected: "sv.toml:1:29: unexpected annotation 'money' for 'name'",
+		},
+		{
+			name:     "multi-line string",
+			toml:     "info = \"\"\"\nFirst line\nsecond {{one|two|three}}\"\"\"",
+			expected: "sv.toml:3:8: expected 2 plural forms",
+		},
+		{
+			name:     "invalid TOML",
+			toml:     "title = \"Hello\"\nmessage = ",
+			expected: "sv.toml:2:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseContent("sv.toml", "sv", tt.toml)
+			if len(result.Errors) != 1 {
+				t.Fatalf("Expected one error, but got: %v", result.Errors)
+			}
+			if !strings.Contains(result.Errors[0].Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, but got %q", tt.expected, result.Errors[0].Error())
+			}
+		})
+	}
+}
+
+func TestParseContent_NestedSections(t *testing.T) {
+	result := parseContent("en.toml", "en", "[settings]\ntitle = \"Settings\"\n\n[settings.account.security]\ntitle = \"Security\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	settings := result.sections["settings"]
+	if _, exists := settings.funcs["title"]; !exists {
+		t.Errorf("Expected settings.title, but got: %v", settings.funcs)
+	}
+	if _, exists := settings.sections["account"].sections["security"].funcs["title"]; !exists {
+		t.Errorf("Expected settings.account.security.title, but got: %v", settings.sections)
+	}
+}
+
+func TestValidateAllLocales_NestedSections(t *testing.T) {
+	base := parseContent("en.toml", "en", "[settings.account]\ntitle = \"Account\"\n\n[settings.account.security]\ntitle = \"Security\"")
+	other := parseContent("sv.toml", "sv", "[settings.account]\ntitle = \"Konto\"\n\n[settings.account.privacy]\ntitle = \"Integritet\"")
+
+	errors := validateAllLocales("en", map[string]TomlParseResult{"en": base, "sv": other}, nil, func(string) bool { return false })
+	var messages []string
+	for _, err := range errors["sv"] {
+		messages = append(messages, err.Error())
+	}
+	joined := strings.Join(messages, "\n")
+	for _, expected := range []string{
+		"sv is missing section [settings.account.security]",
+		"sv has unknown section [settings.account.privacy]",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected error containing '%s', but got: %v", expected, messages)
+		}
+	}
+}
+
+func TestValidateAllLocales_Fallbacks(t *testing.T) {
+	localeToData := map[string]TomlParseResult{
+		"sv":    parseContent("sv.toml", "sv", "title = \"Hej\"\n\n[menu]\nfiles = \"{count} {{fil|filer}}\""),
+		"en":    parseContent("en.toml", "en", "title = \"Hello\"\n\n[menu]\nfiles = \"{count} file{{s}}\""),
+		"en_uk": parseContent("en_uk.toml", "en_uk", "title = \"Hello, mate\""),
+		"pt_br": parseContent("pt_br.toml", "pt_br", "[menu]\nfiles = \"{count} arquivo{{s}}\""),
+	}
+	if errors := validateAllLocales("sv", localeToData, nil, func(string) bool { return false }); len(errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	enUk := localeToData["en_uk"]
+	if enUk.Fallback != "en" || enUk.root["title"].Fallback != "" {
+		t.Errorf("Expected en_uk to fall back to en for everything but title, but got: %v", enUk.InheritedKeys())
+	}
+	if body := enUk.sections["menu"].funcs["files"].Body; !strings.Contains(body, "return (&TranslationEn_menu{}).Files(count)") {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+
+	ptBr := localeToData["pt_br"]
+	if ptBr.Fallback != "sv" || strings.Join(ptBr.InheritedKeys(), ",") != "title" {
+		t.Errorf("Expected pt_br to fall back to sv for title, but got %s: %v", ptBr.Fallback, ptBr.InheritedKeys())
+	}
+}
+
+func TestParentLocales(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected []string
+	}{
+		{locale: "en_uk", expected: []string{"en"}},
+		{locale: "zh_hant_tw", expected: []string{"zh_hant"}},
+		{locale: "zh_tw", expected: []string{"zh_hant"}},
+		{locale: "zh_hant", expected: []string{}},
+		{locale: "zh_cn", expected: []string{"zh"}},
+		{locale: "sr_latn", expected: []string{}},
+		{locale: "sr_latn_rs", expected: []string{"sr_latn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			if parents := parentLocales(tt.locale); !reflect.DeepEqual(parents, tt.expected) {
+				t.Errorf("Expected parents %v, got %v", tt.expected, parents)
+			}
+		})
+	}
+
+	// Locales in another script than their language fall back to the base locale
+	localeToData := map[string]TomlParseResult{
+		"en":      parseContent("en.toml", "en", "title = \"Hello\"\nbye = \"Bye\""),
+		"zh":      parseContent("zh.toml", "zh", "title = \"你好\"\nbye = \"再见\""),
+		"zh_hant": parseContent("zh_hant.toml", "zh_hant", "title = \"你好\""),
+	}
+	if errors := validateAllLocales("en", localeToData, nil, func(string) bool { return false }); len(errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+	if zhHant := localeToData["zh_hant"]; zhHant.Fallback != "en" || strings.Join(zhHant.InheritedKeys(), ",") != "bye" {
+		t.Errorf("Expected zh_hant to fall back to en for bye, but got %s: %v", zhHant.Fallback, zhHant.InheritedKeys())
+	}
+}
+
+func TestValidateAllLocales_ConfiguredFallbacks(t *testing.T) {
+	newLocales := func() map[string]TomlParseResult {
+		return map[string]TomlParseResult{
+			"sv":    parseContent("sv.toml", "sv", "title = \"Hej\"\ngreeting = \"Hej {name}\""),
+			"de":    parseContent("de.toml", "de", "title = \"Hallo\"\ngreeting = \"Hallo {name}\""),
+			"de_ch": parseContent("de_ch.toml", "de_ch", "title = \"Grüezi\""),
+			"gsw":   parseContent("gsw.toml", "gsw", ""),
+		}
+	}
+
+	// gsw falls back to de_ch, which falls back to de
+	localeToData := newLocales()
+	if errors := validateAllLocales("sv", localeToData, map[string]string{"gsw": "de_ch"}, func(string) bool { return false }); len(errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+	gsw := localeToData["gsw"]
+	if gsw.Fallback != "de_ch" || strings.Join(gsw.InheritedKeys(), ",") != "greeting,title" {
+		t.Errorf("Expected gsw to fall back to de_ch, but got %s: %v", gsw.Fallback, gsw.InheritedKeys())
+	}
+	if body := gsw.root["greeting"].Body; !strings.Contains(body, "return (&TranslationDeCh{}).Greeting(name)") {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+
+	tests := []struct {
+		name      string
+		fallbacks map[string]string
+		expected  string
+	}{
+		{name: "cycle", fallbacks: map[string]string{"gsw": "de_ch", "de": "gsw"}, expected: "has a cycle of fallbacks through"},
+		{name: "unknown locale", fallbacks: map[string]string{"gsw": "fr"}, expected: "gsw falls back to fr, which doesn't exist"},
+		{name: "base locale", fallbacks: map[string]string{"sv": "de", "gsw": "de"}, expected: "base locale sv can't fall back to de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateAllLocales("sv", newLocales(), tt.fallbacks, func(string) bool { return false })
+			found := false
+			for _, localeErrors := range errors {
+				for _, err := range localeErrors {
+					found = found || strings.Contains(err.Error(), tt.expected)
+				}
+			}
+			if !found {
+				t.Errorf("Expected error containing '%s', but got: %v", tt.expected, errors)
+			}
+		})
+	}
+}
+
+func TestValidateAllLocales_AllowMissing(t *testing.T) {
+	newLocales := func() map[string]TomlParseResult {
+		return map[string]TomlParseResult{
+			"sv": parseContent("sv.toml", "sv", "title = \"Hej\"\ngreeting = \"Hej {name}\"\n\n[menu]\nopen = \"Öppna\""),
+			"de": parseContent("de.toml", "de", "title = \"Hallo\""),
+		}
+	}
+
+	if errors := validateAllLocales("sv", newLocales(), nil, func(string) bool { return false }); len(errors["de"]) != 2 {
+		t.Errorf("Expected 2 errors when missing translations aren't allowed, but got: %v", errors)
+	}
+
+	localeToData := newLocales()
+	if errors := validateAllLocales("sv", localeToData, nil, func(locale string) bool { return locale == "de" }); len(errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+	de := localeToData["de"]
+	if missing := strings.Join(de.MissingKeys(), ","); missing != "greeting,menu.open" {
+		t.Errorf("Unexpected missing keys: %s", missing)
+	}
+	if body := de.root["greeting"].Body; !strings.Contains(body, "return (&TranslationSv{}).Greeting(name)") {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+}
+
+func TestProcessTomlDir_Locales(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sv.toml":         "title = \"Hej\"",
+		"zh_hant.toml":    "title = \"你好\"",
+		"zh_Hant_TW.toml": "",
+		"es-419.toml":     "title = \"Hola\"",
+		"fil.toml":        "title = \"Kumusta\"",
+		"english.toml":    "title = \"Hello\"",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ProcessTomlDir(dir, ProcessOptions{BaseLocale: "sv"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedTags := map[string]string{
+		"sv":         "sv",
+		"zh_hant":    "zh-Hant",
+		"zh_hant_tw": "zh-Hant-TW",
+		"es_419":     "es-419",
+		"fil":        "fil",
+	}
+	if len(result.ParsedFuncsByLocale) != len(expectedTags) {
+		t.Errorf("Expected locales %v, but got %v", expectedTags, result.ParsedFuncsByLocale)
+	}
+	for locale, tag := range expectedTags {
+		if data, exists := result.ParsedFuncsByLocale[locale]; !exists || data.Tag != tag {
+			t.Errorf("Expected %s with tag %s, but got '%s'", locale, tag, data.Tag)
+		}
+	}
+	if fallback := result.ParsedFuncsByLocale["zh_hant_tw"].Fallback; fallback != "zh_hant" {
+		t.Errorf("Expected zh_hant_tw to fall back to zh_hant, but was '%s'", fallback)
+	}
+	if fallback := result.ParsedFuncsByLocale["es_419"].Fallback; fallback != "sv" {
+		t.Errorf("Expected es_419 to fall back to sv, but was '%s'", fallback)
+	}
+}
+
+func TestProcessTomlDir_ErrorOrder(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sv.toml": "b = \"{count} {{a|b}}\"\na = \"{x\"\n",
+		"en.toml": "b = \"{count} thing{{s\"\na = \"{y\"\n",
+		"de.toml": "c = \"Hallo\"\nd = \"Tschüss\"\n",
+		"fi.toml": "e = \"Hei\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var first string
+	for i := 0; i < 20; i++ {
+		_, err := ProcessTomlDir(dir, ProcessOptions{BaseLocale: "sv"})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if i == 0 {
+			first = err.Error()
+		} else if err.Error() != first {
+			t.Fatalf("Expected the same error report on every run, but got:\n%s\nand:\n%s", first, err.Error())
+		}
+	}
+
+	en, sv := strings.Index(first, "en.toml"), strings.Index(first, "sv.toml")
+	if en == -1 || sv == -1 || en > sv {
+		t.Errorf("Expected errors to be sorted by file, got:\n%s", first)
+	}
+	aIndex, bIndex := strings.Index(first, "a = "), strings.Index(first, "b = ")
+	if aIndex == -1 || bIndex == -1 || aIndex > bIndex {
+		t.Errorf("Expected errors to be sorted by key, got:\n%s", first)
+	}
+}
+
+func TestParseContent_TypedSubstitutions(t *testing.T) {
+	result := parseContent("en.toml", "en", "receipt = \"{user:Stringer} paid {price:float} for {n:int} items at {when:time}, {price}\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	trFunc := result.root["receipt"]
+	if sig := trFunc.Signature(); sig != "Receipt(user fmt.Stringer, price float64, n int, when time.Time) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+	if imports := strings.Join(trFunc.SignatureImports(), ","); imports != "fmt,time" {
+		t.Errorf("Unexpected imports: %s", imports)
+	}
+	if !strings.Contains(trFunc.Body, `"%s paid %s for %s items at %s, %s", user, formatFloat(price, ".", ",", 1, 3), formatInt(n, ",", 1, 3), formatTime(when, "h:mm:ss a", &dateNamesEn), formatFloat(price, ".", ",", 1, 3)`) {
+		t.Errorf("Unexpected body:\n%s", trFunc.Body)
+	}
+}
+
+func TestParseContent_NumberFormatting(t *testing.T) {
+	result := parseContent("de.toml", "de", "total = \"{count} items for {price:float} (id {count:raw}, {price:raw})\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	body := result.root["total"].Body
+	if !strings.Contains(body, `"%s items for %s (id %d, %s)", formatInt(count, ".", 1, 3), formatFloat(price, ",", ".", 1, 3), count, formatFloat(price, ".", "", 1, 3)`) {
+		t.Errorf("Unexpected body:\n%s", body)
+	}
+}
+
+func TestParseContent_IndianGrouping(t *testing.T) {
+	result := parseContent("hi.toml", "hi", "files = \"{count} फ़ाइलें\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if body := result.root["files"].Body; !strings.Contains(body, `formatInt(count, ",", 1, 2)`) {
+		t.Errorf("Expected groups of two digits, got:\n%s", body)
+	}
+}
+
+func TestNumberSymbolsFor(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected numberSymbols
+	}{
+		{locale: "es", expected: numberSymbols{Decimal: ",", Group: ".", MinGrouping: 2}},
+		{locale: "es_419", expected: symbolsPointComma},
+		{locale: "es_mx", expected: symbolsPointComma},
+		{locale: "es_ar", expected: symbolsCommaPoint},
+		{locale: "es_us", expected: symbolsPointComma},
+		{locale: "es_es", expected: numberSymbols{Decimal: ",", Group: ".", MinGrouping: 2}},
+		{locale: "pt_br", expected: symbolsCommaPoint},
+		{locale: "ca", expected: symbolsCommaPoint},
+		{locale: "et", expected: numberSymbols{Decimal: ",", Group: nbsp, MinGrouping: 2}},
+		{locale: "en_in", expected: numberSymbols{Decimal: ".", Group: ",", MinGrouping: 1, SecondaryGrouping: 2}},
+		{locale: "xx", expected: symbolsPointComma},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			if symbols := numberSymbolsFor(tt.locale); symbols != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, symbols)
+			}
+		})
+	}
+}
+
+func TestDateConventionsFor(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+		known    bool
+	}{
+		{locale: "ru", expected: "d MMMM y 'г'.", known: true},
+		{locale: "pt_br", expected: "d 'de' MMMM 'de' y", known: true},
+		{locale: "zh_cn", expected: "y年M月d日", known: true},
+		{locale: "zh_hant", expected: "y-MM-dd", known: false},
+		{locale: "th", expected: "y-MM-dd", known: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			conventions, known := dateConventionsFor(tt.locale)
+			if pattern := conventions.datePattern("date", WidthLong); pattern != tt.expected || known != tt.known {
+				t.Errorf("Expected %s (known: %v), got %s (known: %v)", tt.expected, tt.known, pattern, known)
+			}
+		})
+	}
+}
+
+func TestParseContent_DateFormatting(t *testing.T) {
+	result := parseContent("sv.toml", "sv", "due = \"{what} ska vara klar {when:date:long}, senast {when:time:short} ({when:datetime})\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	trFunc := result.root["due"]
+	if sig := trFunc.Signature(); sig != "Due(what string, when time.Time) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+	for _, expected := range []string{
+		`formatTime(when, "d MMMM y", &dateNamesSv)`,
+		`formatTime(when, "HH:mm", &dateNamesSv)`,
+		`formatTime(when, "d MMM y HH:mm:ss", &dateNamesSv)`,
+	} {
+		if !strings.Contains(trFunc.Body, expected) {
+			t.Errorf("Expected body to contain %q, got:\n%s", expected, trFunc.Body)
+		}
+	}
+}
+
+func TestValidateSection_InheritsDateStyles(t *testing.T) {
+	base := parseContent("en.toml", "en", "due = \"Due {when:date:full}\"")
+	other := parseContent("de.toml", "de", "due = \"Fällig am {when}, {when:time}\"")
+
+	if errors := validateSection(base.root, other.root, "", "de"); len(errors) != 0 {
+		t.Fatalf("Expected no validation errors, got: %v", errors)
+	}
+
+	body := other.root["due"].Body
+	if !strings.Contains(body, `formatTime(when, "EEEE, d. MMMM y", &dateNamesDe), formatTime(when, "HH:mm:ss", &dateNamesDe)`) {
+		t.Errorf("Expected unannotated substitution to use the style of the base locale, got:\n%s", body)
+	}
+}
+
+func TestParseContent_Select(t *testing.T) {
+	result := parseContent("de.toml", "de", "arrived = \"{gender, select, female {Sie} male {Er} other {Sie}} ist in {city} angekommen\"")
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	trFunc := result.root["arrived"]
+	if sig := trFunc.Signature(); sig != "Arrived(gender string, city string) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+	expected := "\tswitch gender {\n\tcase \"female\":\n\t\tselect0 = \"Sie\"\n\tcase \"male\":\n\t\tselect0 = \"Er\"\n\tdefault:\n\t\tselect0 = \"Sie\"\n\t}\n"
+	if !strings.Contains(trFunc.Body, expected) {
+		t.Errorf("Expected body to contain %q, got:\n%s", expected, trFunc.Body)
+	}
+}
+
+func TestValidateSection_SelectOptions(t *testing.T) {
+	base := parseContent("en.toml", "en", "arrived = \"{gender, select, female {She} male {He} other {They}} arrived\"")
+
+	tests := []struct {
+		name          string
+		toml          string
+		errorContains string
+	}{
+		{name: "same options", toml: "arrived = \"{gender, select, male {Er} female {Sie} other {Sie}} ist angekommen\""},
+		{name: "missing option", toml: "arrived = \"{gender, select, male {Er} other {Sie}} ist angekommen\"", errorContains: "Should be [female, male, other], but was [male, other]"},
+		{name: "extra option", toml: "arrived = \"{gender, select, female {Sie} male {Er} divers {Sie} other {Sie}} ist angekommen\"", errorContains: "Should be [female, male, other], but was [divers, female, male, other]"},
+		{name: "no select", toml: "arrived = \"{gender} ist angekommen\"", errorContains: "Should be [female, male, other], but was []"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := parseContent("de.toml", "de", tt.toml)
+			errors := validateSection(base.root, other.root, "", "de")
+			if tt.errorContains == "" {
+				if len(errors) != 0 {
+					t.Errorf("Expected no validation errors, got: %v", errors)
+				}
+				return
+			}
+			if len(errors) != 1 || !strings.Contains(errors[0].Error(), tt.errorContains) {
+				t.Errorf("Expected error containing '%s', got: %v", tt.errorContains, errors)
+			}
+		})
+	}
+}
+
+func TestParseContent_EscapedBraces(t *testing.T) {
+	result := parseContent("en.toml", "en", `css = '{name} is styled with a \{ color: {color} \}'`)
+	if len(result.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	trFunc := result.root["css"]
+	if sig := trFunc.Signature(); sig != "Css(name string, color string) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+	if trFunc.DocString != "// {name} is styled with a { color: {color} }" {
+		t.Errorf("Unexpected docstring: %s", trFunc.DocString)
+	}
+	if !strings.Contains(trFunc.Body, `"%s is styled with a { color: %s }", name, color`) {
+		t.Errorf("Unexpected body:\n%s", trFunc.Body)
+	}
+}
+
 func TestValidateSection_MismatchedSignatures(t *testing.T) {
 	// Test that validation catches signature mismatches between locales
 	base := map[string]TranslateFunc{
@@ -159,3 +714,78 @@ func TestValidateSection_ExtraTranslations(t *testing.T) {
 		t.Errorf("Expected unknown translation error, got: %v", errors[0])
 	}
 }
+
+func TestValidateSection_ParamTypeMismatch(t *testing.T) {
+	base := parseContent("en.toml", "en", "summary = \"{files} file{{files: s}}\"")
+	other := parseContent("sv.toml", "sv", "summary = \"{files:string} filer\"")
+
+	errors := validateSection(base.root, other.root, "", "sv")
+	if len(errors) != 1 {
+		t.Fatalf("Expected one validation error, got: %v", errors)
+	}
+	if !strings.Contains(errors[0].Error(), "'files' is int in the base locale but string here") {
+		t.Errorf("Expected parameter type hint, got: %v", errors[0])
+	}
+}
+
+func TestValidateSection_InheritsParamTypes(t *testing.T) {
+	base := parseContent("en.toml", "en", "price = \"{item} costs {price:float}, {files} file{{files: s}}\"")
+	other := parseContent("sv.toml", "sv", "price = \"{item} kostar {price}, {files} filer\"")
+
+	if errors := validateSection(base.root, other.root, "", "sv"); len(errors) != 0 {
+		t.Fatalf("Expected no validation errors, got: %v", errors)
+	}
+
+	trFunc := other.root["price"]
+	if sig := trFunc.Signature(); sig != "Price(item string, price float64, files int) string" {
+		t.Errorf("Unexpected signature: %s", sig)
+	}
+	if !strings.Contains(trFunc.Body, `"%s kostar %s, %s filer", item, formatFloat(price, ",", "\u00a0", 1, 3), formatInt(files, "\u00a0", 1, 3)`) {
+		t.Errorf("Expected body to use the inherited types, got:\n%s", trFunc.Body)
+	}
+}
+
+func TestProcessTomlDir_ExcludeAndAliases(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sv.toml":       "title = \"Hej\"",
+		"en.toml":       "title = \"Hello\"",
+		"en_draft.toml": "title = ",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ProcessTomlDir(dir, ProcessOptions{BaseLocale: "sv", Exclude: []string{"*_draft.toml"}, Aliases: map[string]string{"nb": "sv", "en_us": "en"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ParsedFuncsByLocale) != 2 {
+		t.Errorf("Expected the draft to be excluded, but got %v", result.ParsedFuncsByLocale)
+	}
+	if result.Aliases["nb"] != "sv" || result.Aliases["en-US"] != "en" {
+		t.Errorf("Unexpected aliases: %v", result.Aliases)
+	}
+
+	tests := []struct {
+		name          string
+		options       ProcessOptions
+		errorContains string
+	}{
+		{name: "alias of unknown locale", options: ProcessOptions{Aliases: map[string]string{"nb": "da"}}, errorContains: "alias nb is for da, which doesn't exist"},
+		{name: "alias of existing locale", options: ProcessOptions{Aliases: map[string]string{"en": "sv"}}, errorContains: "alias en is already a locale"},
+		{name: "invalid exclude", options: ProcessOptions{Exclude: []string{"[.toml"}}, errorContains: "invalid exclude pattern"},
+		{name: "invalid fallback", options: ProcessOptions{Fallbacks: map[string]string{"en": "english"}}, errorContains: "invalid fallback for en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.BaseLocale = "sv"
+			tt.options.Exclude = append(tt.options.Exclude, "*_draft.toml")
+			if _, err := ProcessTomlDir(dir, tt.options); err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing '%s', but got: %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TranslationFile is a TOML file with the translations of a locale.
+type TranslationFile struct {
+	Locale string // Locale as used in file names and identifiers, e.g. "zh_hant_tw"
+	Tag    string // Canonical BCP 47 tag of the locale, e.g. "zh-Hant-TW"
+	Path   string
+}
+
+// FindTranslationFiles returns the TOML files in a dir, sorted by file name. Excluded files, and files that
+// aren't named after a locale, are left out.
+func FindTranslationFiles(tomlDir string, exclude []string) ([]TranslationFile, error) {
+	paths, err := filepath.Glob(filepath.Join(tomlDir, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+	paths, err = excludeFiles(paths, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]TranslationFile, 0, len(paths))
+	seenLocales := make(map[string]bool)
+	for _, path := range paths {
+		// Locales are canonicalized since filenames are case-insensitive on some systems, and can use either separator
+		tag, locale, err := canonicalLocale(strings.TrimSuffix(filepath.Base(path), ".toml"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ignoring file %s (%s)\n", path, err)
+			continue
+		}
+		if seenLocales[locale] {
+			fmt.Fprintf(os.Stderr, "ignoring duplicate locale %s from file %s\n", locale, path)
+			continue
+		}
+		seenLocales[locale] = true
+		files = append(files, TranslationFile{Locale: locale, Tag: tag, Path: path})
+	}
+	return files, nil
+}
+
+func excludeFiles(files []string, patterns []string) ([]string, error) {
+	included := make([]string, 0, len(files))
+	for _, file := range files {
+		excluded := false
+		for _, pattern := range patterns {
+			matches, err := filepath.Match(pattern, filepath.Base(file))
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude pattern '%s': %w", pattern, err)
+			}
+			excluded = excluded || matches
+		}
+		if !excluded {
+			included = append(included, file)
+		}
+	}
+	return included, nil
+}
+
+// FindTranslationFile returns the file of a locale, which can be in any form, e.g. "en-GB" or "en_gb".
+func FindTranslationFile(files []TranslationFile, name string) (TranslationFile, bool, error) {
+	_, locale, err := canonicalLocale(name)
+	if err != nil {
+		return TranslationFile{}, false, err
+	}
+	for _, file := range files {
+		if file.Locale == locale {
+			return file, true, nil
+		}
+	}
+	return TranslationFile{}, false, nil
+}
+
+// BaseTranslationFile returns the file of the base locale, which is the first file unless a base locale is given.
+func BaseTranslationFile(files []TranslationFile, baseLocale string) (TranslationFile, error) {
+	if baseLocale == "" {
+		if len(files) == 0 {
+			return TranslationFile{}, fmt.Errorf("no translation files found")
+		}
+		return files[0], nil
+	}
+	file, exists, err := FindTranslationFile(files, baseLocale)
+	if err != nil {
+		return TranslationFile{}, fmt.Errorf("invalid base locale: %w", err)
+	}
+	if !exists {
+		return TranslationFile{}, fmt.Errorf("base locale '%s' not found", baseLocale)
+	}
+	return file, nil
+}
+
+// NewTranslationFile returns the file that a locale, in any form, would have in a dir.
+func NewTranslationFile(tomlDir string, name string) (TranslationFile, error) {
+	tag, locale, err := canonicalLocale(name)
+	if err != nil {
+		return TranslationFile{}, err
+	}
+	return TranslationFile{Locale: locale, Tag: tag, Path: filepath.Join(tomlDir, locale+".toml")}, nil
+}
+
+// ReadTranslations returns the translations in a file by their keys, e.g. "settings.account.title", as they're
+// written in it.
+func ReadTranslations(path string) (map[string]string, error) {
+	var content map[string]any
+	if _, err := toml.DecodeFile(path, &content); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	translations := make(map[string]string)
+	var flatten func(prefix string, table map[string]any) error
+	flatten = func(prefix string, table map[string]any) error {
+		for key, value := range table {
+			switch value := value.(type) {
+			case string:
+				translations[prefix+key] = value
+			case map[string]any:
+				if err := flatten(prefix+key+".", value); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("expected string for %s%s in %s, but got %T", prefix, key, path, value)
+			}
+		}
+		return nil
+	}
+	if err := flatten("", content); err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// EncodeTranslations encodes translations by their keys as TOML, with a table per section. Untranslated
+// translations are written as comments, for a translator to fill in.
+func EncodeTranslations(translations map[string]string, untranslated map[string]string) ([]byte, error) {
+	content := make(map[string]any)
+	for _, all := range []map[string]string{untranslated, translations} {
+		for key, value := range all {
+			table := content
+			path := strings.Split(key, ".")
+			for i, section := range path[:len(path)-1] {
+				child, ok := table[section].(map[string]any)
+				if !ok {
+					if _, exists := table[section]; exists {
+						return nil, fmt.Errorf("%s can't be both a translation and the section of %s", strings.Join(path[:i+1], "."), key)
+					}
+					child = make(map[string]any)
+					table[section] = child
+				}
+				table = child
+			}
+			if _, isSection := table[path[len(path)-1]].(map[string]any); isSection {
+				return nil, fmt.Errorf("%s can't be both a translation and a section", key)
+			}
+			table[path[len(path)-1]] = value
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(content); err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	previous, prefix := "", ""
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if match := tableRegexp.FindStringSubmatch(line); match != nil {
+			// Nested tables aren't separated by the encoder
+			if previous != "" {
+				sb.WriteString("\n")
+			}
+			prefix = match[1] + "."
+		} else if match := keyRegexp.FindStringSubmatch(line); match != nil {
+			key := prefix + match[1]
+			if _, translated := translations[key]; !translated {
+				sb.WriteString("# ")
+			}
+		}
+		sb.WriteString(line + "\n")
+		previous = line
+	}
+	return []byte(sb.String()), nil
+}
+
+var (
+	tableRegexp = regexp.MustCompile(`^\[([A-Za-z0-9_.-]+)\]$`)
+	keyRegexp   = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=`)
+)
+
+// ReadUntranslated returns the translations that are commented out in a file, like in the files created by
+// the add command, by their keys.
+func ReadUntranslated(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	untranslated := make(map[string]string)
+	prefix := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if match := tableRegexp.FindStringSubmatch(line); match != nil {
+			prefix = match[1] + "."
+			continue
+		}
+		commented := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if !strings.HasPrefix(line, "#") || !keyRegexp.MatchString(commented) {
+			continue
+		}
+		// Other comments can look like translations too, which is fine as long as they don't decode as one
+		var decoded map[string]any
+		if _, err := toml.Decode(commented, &decoded); err != nil {
+			continue
+		}
+		for key, value := range decoded {
+			if value, ok := value.(string); ok {
+				untranslated[prefix+key] = value
+			}
+		}
+	}
+	return untranslated, nil
+}
+
+// ExportCSV writes the translations of all locales as CSV, with a column per locale, base locale first, and a
+// row per key of the base locale. Missing translations are empty.
+func ExportCSV(w io.Writer, files []TranslationFile, base TranslationFile) error {
+	ordered := []TranslationFile{base}
+	for _, file := range files {
+		if file.Locale != base.Locale {
+			ordered = append(ordered, file)
+		}
+	}
+
+	header := []string{"key"}
+	translationsByLocale := make([]map[string]string, len(ordered))
+	for i, file := range ordered {
+		translations, err := ReadTranslations(file.Path)
+		if err != nil {
+			return err
+		}
+		header = append(header, file.Tag)
+		translationsByLocale[i] = translations
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, key := range getKeysSorted(translationsByLocale[0]) {
+		row := []string{key}
+		for _, translations := range translationsByLocale {
+			row = append(row, translations[key])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportCSV reads translations in the format of ExportCSV, by the locales of the columns. Empty cells are
+// left out, so they don't replace existing translations.
+func ImportCSV(r io.Reader) (map[string]map[string]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(records) == 0 || len(records[0]) < 2 || records[0][0] != "key" {
+		return nil, fmt.Errorf("expected a header with 'key' and then the locales, like 'key,sv,en'")
+	}
+
+	header := records[0]
+	translationsByLocale := make(map[string]map[string]string)
+	for _, locale := range header[1:] {
+		if _, _, err := canonicalLocale(locale); err != nil {
+			return nil, fmt.Errorf("invalid column: %w", err)
+		}
+		translationsByLocale[locale] = make(map[string]string)
+	}
+	for line, record := range records[1:] {
+		key := record[0]
+		if key == "" {
+			return nil, fmt.Errorf("missing key on line %d", line+2)
+		}
+		for i, value := range record[1:] {
+			if value != "" {
+				translationsByLocale[header[i+1]][key] = value
+			}
+		}
+	}
+	return translationsByLocale, nil
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodeTranslations(t *testing.T) {
+	translations := map[string]string{
+		"title":                      "Välkommen",
+		"multiline":                  "Hej {name},\n\"du\"",
+		"settings.title":             "Inställningar",
+		"settings.account.sessions":  "{count} {{session|sessioner}}",
+		"settings.account.braces":    `Använd \{name\}`,
+		"menu.message":               "{name} har {count} notis{{er}}",
+		"settings.account.pref.mode": "Läge",
+	}
+
+	encoded, err := EncodeTranslations(translations, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "sv.toml")
+	if err := os.WriteFile(path, encoded, 0644); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadTranslations(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, encoded)
+	}
+	if len(decoded) != len(translations) {
+		t.Errorf("Expected %d translations, but got %v", len(translations), decoded)
+	}
+	for key, value := range translations {
+		if decoded[key] != value {
+			t.Errorf("Expected %q for %s, but got %q", value, key, decoded[key])
+		}
+	}
+	if result := parseContent("sv.toml", "sv", string(encoded)); len(result.Errors) != 0 {
+		t.Errorf("Unexpected errors: %v\n%s", result.Errors, encoded)
+	}
+
+	commented, err := EncodeTranslations(nil, translations)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(commented), "\n[settings.account]\n# braces = ") || strings.Contains(string(commented), "\ntitle =") {
+		t.Errorf("Expected translations to be commented, but got:\n%s", commented)
+	}
+	if err := os.WriteFile(path, append([]byte("# Translations for sv, from en.\n\n"), commented...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	untranslated, err := ReadUntranslated(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(untranslated) != len(translations) || untranslated["settings.account.braces"] != translations["settings.account.braces"] {
+		t.Errorf("Expected the commented translations, but got %v", untranslated)
+	}
+
+	// Translating some keeps the rest commented
+	delete(untranslated, "settings.title")
+	partial, err := EncodeTranslations(map[string]string{"settings.title": "Settings"}, untranslated)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(partial), "\n[settings]\ntitle = \"Settings\"\n") || !strings.Contains(string(partial), "\n# title = \"Välkommen\"\n") {
+		t.Errorf("Expected only the translated translation to be uncommented, but got:\n%s", partial)
+	}
+
+	for _, colliding := range []map[string]string{
+		{"menu": "Meny", "menu.title": "Titel"},
+		{"menu.title": "Titel", "menu": "Meny"},
+		{"menu.title.short": "Titel", "menu.title": "Titel"},
+	} {
+		if _, err := EncodeTranslations(colliding, nil); err == nil || !strings.Contains(err.Error(), "can't be both a translation and") {
+			t.Errorf("Expected an error for %v, got: %v", colliding, err)
+		}
+	}
+}
+
+func TestExportImportCSV(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sv.toml":    "title = \"Hej, \\\"du\\\"\"\n\n[menu]\nfiles = \"{count} {{fil|filer}}\"",
+		"en_uk.toml": "title = \"Hello\"",
+		"notes.toml": "title = \"Not a locale\"",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	translationFiles, err := FindTranslationFiles(dir, []string{"notes.toml"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(translationFiles) != 2 || translationFiles[0].Locale != "en_gb" || translationFiles[0].Tag != "en-GB" {
+		t.Fatalf("Unexpected files: %v", translationFiles)
+	}
+	base, err := BaseTranslationFile(translationFiles, "sv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var exported strings.Builder
+	if err := ExportCSV(&exported, translationFiles, base); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "key,sv,en-GB\nmenu.files,{count} {{fil|filer}},\ntitle,\"Hej, \"\"du\"\"\",Hello\n"
+	if exported.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, exported.String())
+	}
+
+	imported, err := ImportCSV(strings.NewReader(strings.Replace(exported.String(), "filer}},", "filer}},{count} file{{s}}", 1)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(imported["sv"]) != 2 || imported["en-GB"]["menu.files"] != "{count} file{{s}}" || imported["en-GB"]["title"] != "Hello" {
+		t.Errorf("Unexpected import: %v", imported)
+	}
+
+	for _, invalid := range []string{"", "locale,sv\n", "key,swedish\n", "key,sv\n,Hej\n"} {
+		if _, err := ImportCSV(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}